@@ -0,0 +1,493 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	HashBatchTool = ToolDescription{
+		Name:        "hash_batch",
+		Description: "Hash many strings, files and directory trees with one or more algorithms in a single call. Returns a checksum manifest in GNU (`sha256sum`), BSD tag (`shasum --tag`) or JSON format. `verify_manifest` can check the file entries later and reports the entries for `texts` as unverifiable. At least one of texts, paths or directory must be provided.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"texts": map[string]interface{}{
+					"type":        "array",
+					"description": "strings to hash, listed in the manifest as text[0], text[1], ... These entries name no file, so verify_manifest reports them as unverifiable",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "how the `texts` are encoded (default: utf8)",
+					"enum":        []string{"utf8", "hex", "base64"},
+					"default":     "utf8",
+				},
+				"paths": map[string]interface{}{
+					"type":        "array",
+					"description": "files to hash. The file paths must either be absolute or relative to the directory this servlet has access to. This servlet understands the following root directories: /, /home/, and /tmp",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"directory": map[string]interface{}{
+					"type":        "string",
+					"description": "a directory to walk recursively, hashing every regular file. Manifest names are relative to it",
+				},
+				"include_hidden": map[string]interface{}{
+					"type":        "boolean",
+					"description": "also hash files and directories whose names start with a dot, such as .git (default: false)",
+					"default":     false,
+				},
+				"algorithms": map[string]interface{}{
+					"type":        "array",
+					"description": "the hash algorithms to compute (default: [sha256])",
+					"items": map[string]interface{}{
+						"type": "string",
+						"enum": digestAlgorithms,
+					},
+				},
+				"format": map[string]interface{}{
+					"type":        "string",
+					"description": "gnu prints `<hash>  <name>` and supports a single algorithm, bsd prints `SHA256 (<name>) = <hash>` (default: gnu)",
+					"enum":        []string{"gnu", "bsd", "json"},
+					"default":     "gnu",
+				},
+			},
+		},
+	}
+	VerifyManifestTool = ToolDescription{
+		Name:        "verify_manifest",
+		Description: "Check files against a checksum manifest in GNU (`sha256sum`), BSD tag or `hash_batch` JSON format, reporting mismatched and missing files. The text[N] entries hash_batch lists for `texts` are reported as unverifiable and do not affect valid. Either manifest or manifest_path must be provided.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"manifest": map[string]interface{}{
+					"type":        "string",
+					"description": "the manifest contents",
+				},
+				"manifest_path": map[string]interface{}{
+					"type":        "string",
+					"description": "path to the manifest file",
+				},
+				"base_directory": map[string]interface{}{
+					"type":        "string",
+					"description": "directory that relative names in the manifest are resolved against. Defaults to the directory of manifest_path, or /",
+				},
+				"algorithm": map[string]interface{}{
+					"type":        "string",
+					"description": "the algorithm of a GNU format manifest. Defaults to guessing from the hash length (md5, sha1, sha224, sha256, sha384 or sha512)",
+					"enum":        digestAlgorithms,
+				},
+			},
+		},
+	}
+)
+
+// ManifestEntry holds the hashes of one input.
+type ManifestEntry struct {
+	Name   string            `json:"name"`
+	Size   int64             `json:"size"`
+	Hashes map[string]string `json:"hashes"`
+}
+
+// Manifest is the JSON format of hash_batch.
+type Manifest struct {
+	Algorithms []string        `json:"algorithms"`
+	Entries    []ManifestEntry `json:"entries"`
+}
+
+// ManifestVerification is returned by verify_manifest.
+type ManifestVerification struct {
+	Valid        bool               `json:"valid"`
+	Checked      int                `json:"checked"`
+	Matched      int                `json:"matched"`
+	Mismatched   []ManifestMismatch `json:"mismatched,omitempty"`
+	Missing      []ManifestMissing  `json:"missing,omitempty"`
+	Unverifiable []string           `json:"unverifiable,omitempty"`
+	Skipped      []string           `json:"skipped_lines,omitempty"`
+}
+
+// ManifestMismatch is a file whose hash differs from the manifest.
+type ManifestMismatch struct {
+	Name      string `json:"name"`
+	Algorithm string `json:"algorithm"`
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual"`
+}
+
+// ManifestMissing is a file that could not be read.
+type ManifestMissing struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// maxBatchFiles bounds directory walks so a stray `/` cannot hash the whole
+// filesystem.
+const maxBatchFiles = 10000
+
+// bsdTags are the algorithm names coreutils and shasum print in BSD tag
+// manifests.
+var bsdTags = map[string]string{
+	"md5":         "MD5",
+	"sha1":        "SHA1",
+	"sha224":      "SHA224",
+	"sha256":      "SHA256",
+	"sha384":      "SHA384",
+	"sha512":      "SHA512",
+	"sha512-224":  "SHA512/224",
+	"sha512-256":  "SHA512/256",
+	"sha3-224":    "SHA3-224",
+	"sha3-256":    "SHA3-256",
+	"sha3-384":    "SHA3-384",
+	"sha3-512":    "SHA3-512",
+	"blake2b":     "BLAKE2b",
+	"blake2b-256": "BLAKE2b-256",
+	"blake2b-384": "BLAKE2b-384",
+	"blake2b-512": "BLAKE2b",
+	"blake2s-256": "BLAKE2s-256",
+	"blake3":      "BLAKE3",
+}
+
+// gnuAlgorithms guesses the algorithm of a GNU manifest line from the hex
+// length of its hash.
+var gnuAlgorithms = map[int]string{
+	32:  "md5",
+	40:  "sha1",
+	56:  "sha224",
+	64:  "sha256",
+	96:  "sha384",
+	128: "sha512",
+}
+
+var (
+	bsdLine = regexp.MustCompile(`^([A-Za-z0-9/-]+) ?\((.*)\) ?= ?([0-9a-fA-F]+)$`)
+	gnuLine = regexp.MustCompile(`^\\?([0-9a-fA-F]+) [ *](.*)$`)
+	// textEntry matches the names hash_batch gives to its texts
+	textEntry = regexp.MustCompile(`^text\[[0-9]+\]$`)
+)
+
+func bsdTagAlgorithm(tag string) (string, bool) {
+	for algorithm, t := range bsdTags {
+		if strings.EqualFold(t, tag) && algorithm != "blake2b-512" {
+			return algorithm, true
+		}
+	}
+	algorithm := strings.ToLower(tag)
+	_, err := newDigest(algorithm, 0)
+	return algorithm, err == nil
+}
+
+// hashReader computes every algorithm over r in a single pass.
+func hashReader(r io.Reader, algorithms []string) (map[string]string, int64, error) {
+	hashers := make([]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, len(algorithms))
+	for i, algorithm := range algorithms {
+		h, err := newDigest(algorithm, 0)
+		if err != nil {
+			return nil, 0, err
+		}
+		hashers[i] = h
+		writers[i] = h
+	}
+	n, err := io.Copy(io.MultiWriter(writers...), r)
+	if err != nil {
+		return nil, 0, err
+	}
+	hashes := make(map[string]string, len(algorithms))
+	for i, algorithm := range algorithms {
+		hashes[algorithm] = hex.EncodeToString(hashers[i].Sum(nil))
+	}
+	return hashes, n, nil
+}
+
+func hashFile(path string, algorithms []string) (map[string]string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	return hashReader(f, algorithms)
+}
+
+// stringList returns a string array argument.
+func stringList(args map[string]interface{}, name string) ([]string, error) {
+	raw, exists := args[name]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings", name)
+	}
+	out := make([]string, len(list))
+	for i, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] must be a string", name, i)
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// walkDirectory returns the regular files below dir, relative to it, in
+// lexical order.
+func walkDirectory(dir string, includeHidden bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !includeHidden && path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(files) == maxBatchFiles {
+			return fmt.Errorf("directory contains more than %d files", maxBatchFiles)
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+func runHashBatch(args map[string]interface{}) (CallToolResult, error) {
+	algorithms, err := stringList(args, "algorithms")
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if len(algorithms) == 0 {
+		algorithms = []string{"sha256"}
+	}
+	for _, algorithm := range algorithms {
+		if _, err := newDigest(algorithm, 0); err != nil {
+			return CallToolResult{}, err
+		}
+	}
+	format := "gnu"
+	if formatVal, exists := args["format"].(string); exists && formatVal != "" {
+		format = formatVal
+	}
+	switch format {
+	case "gnu":
+		if len(algorithms) > 1 {
+			return CallToolResult{}, errors.New("gnu format supports a single algorithm, use bsd or json for several")
+		}
+	case "bsd", "json":
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported format %q, expected gnu, bsd or json", format)
+	}
+
+	texts, err := stringList(args, "texts")
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	paths, err := stringList(args, "paths")
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	directory, _ := args["directory"].(string)
+	if len(texts) == 0 && len(paths) == 0 && directory == "" {
+		return CallToolResult{}, errors.New("at least one of texts, paths or directory must be provided")
+	}
+
+	manifest := Manifest{Algorithms: algorithms}
+	encoding, _ := args["encoding"].(string)
+	for i, text := range texts {
+		data, err := decodeInput(text, encoding)
+		if err != nil {
+			return CallToolResult{}, fmt.Errorf("texts[%d]: %w", i, err)
+		}
+		hashes, size, err := hashReader(bytes.NewReader(data), algorithms)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		manifest.Entries = append(manifest.Entries, ManifestEntry{Name: fmt.Sprintf("text[%d]", i), Size: size, Hashes: hashes})
+	}
+	for _, path := range paths {
+		hashes, size, err := hashFile(wasiPath(path), algorithms)
+		if err != nil {
+			return CallToolResult{}, fmt.Errorf("failed to hash %s: %w", path, err)
+		}
+		manifest.Entries = append(manifest.Entries, ManifestEntry{Name: path, Size: size, Hashes: hashes})
+	}
+	if directory != "" {
+		root := wasiPath(directory)
+		includeHidden, _ := args["include_hidden"].(bool)
+		files, err := walkDirectory(root, includeHidden)
+		if err != nil {
+			return CallToolResult{}, fmt.Errorf("failed to walk %s: %w", directory, err)
+		}
+		for _, name := range files {
+			hashes, size, err := hashFile(filepath.Join(root, filepath.FromSlash(name)), algorithms)
+			if err != nil {
+				return CallToolResult{}, fmt.Errorf("failed to hash %s: %w", name, err)
+			}
+			manifest.Entries = append(manifest.Entries, ManifestEntry{Name: name, Size: size, Hashes: hashes})
+		}
+	}
+
+	if format == "json" {
+		return jsonResult(manifest)
+	}
+	var b strings.Builder
+	for _, algorithm := range algorithms {
+		for _, entry := range manifest.Entries {
+			if format == "gnu" {
+				fmt.Fprintf(&b, "%s  %s\n", entry.Hashes[algorithm], entry.Name)
+				continue
+			}
+			tag, ok := bsdTags[algorithm]
+			if !ok {
+				tag = strings.ToUpper(algorithm)
+			}
+			fmt.Fprintf(&b, "%s (%s) = %s\n", tag, entry.Name, entry.Hashes[algorithm])
+		}
+	}
+	return textResult(b.String()), nil
+}
+
+type manifestCheck struct {
+	name      string
+	algorithm string
+	expected  string
+}
+
+// parseManifest reads GNU, BSD tag or JSON manifests. Lines that cannot be
+// understood are returned separately rather than failing the whole check.
+func parseManifest(text, algorithm string) ([]manifestCheck, []string, error) {
+	if isJSON(text) {
+		var manifest Manifest
+		if err := json.Unmarshal([]byte(text), &manifest); err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON manifest: %w", err)
+		}
+		var checks []manifestCheck
+		for _, entry := range manifest.Entries {
+			algorithms := make([]string, 0, len(entry.Hashes))
+			for a := range entry.Hashes {
+				algorithms = append(algorithms, a)
+			}
+			sort.Strings(algorithms)
+			for _, a := range algorithms {
+				checks = append(checks, manifestCheck{name: entry.Name, algorithm: a, expected: entry.Hashes[a]})
+			}
+		}
+		return checks, nil, nil
+	}
+
+	var checks []manifestCheck
+	var skipped []string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := bsdLine.FindStringSubmatch(line); m != nil {
+			if a, ok := bsdTagAlgorithm(m[1]); ok {
+				checks = append(checks, manifestCheck{name: m[2], algorithm: a, expected: strings.ToLower(m[3])})
+				continue
+			}
+		}
+		if m := gnuLine.FindStringSubmatch(line); m != nil {
+			a := algorithm
+			if a == "" {
+				a = gnuAlgorithms[len(m[1])]
+			}
+			if a != "" {
+				checks = append(checks, manifestCheck{name: m[2], algorithm: a, expected: strings.ToLower(m[1])})
+				continue
+			}
+		}
+		skipped = append(skipped, line)
+	}
+	return checks, skipped, scanner.Err()
+}
+
+func runVerifyManifest(args map[string]interface{}) (CallToolResult, error) {
+	text, _ := args["manifest"].(string)
+	base := "/"
+	if manifestPath, exists := args["manifest_path"].(string); exists && manifestPath != "" {
+		fullPath := wasiPath(manifestPath)
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			return CallToolResult{}, fmt.Errorf("failed to read manifest: %w. Trying to read from %s", err, fullPath)
+		}
+		text = string(data)
+		base = filepath.Dir(fullPath)
+	}
+	if strings.TrimSpace(text) == "" {
+		return CallToolResult{}, errors.New("either manifest or manifest_path must be provided")
+	}
+	if baseVal, exists := args["base_directory"].(string); exists && baseVal != "" {
+		base = wasiPath(baseVal)
+	}
+	algorithm, _ := args["algorithm"].(string)
+	if algorithm != "" {
+		if _, err := newDigest(algorithm, 0); err != nil {
+			return CallToolResult{}, err
+		}
+	}
+
+	checks, skipped, err := parseManifest(text, algorithm)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if len(checks) == 0 {
+		return CallToolResult{}, errors.New("manifest contains no checksum lines")
+	}
+
+	result := ManifestVerification{Skipped: skipped}
+	for _, check := range checks {
+		path := check.name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(base, filepath.FromSlash(path))
+		}
+		hashes, _, err := hashFile(path, []string{check.algorithm})
+		if err != nil && textEntry.MatchString(check.name) {
+			// a text hashed by hash_batch, unless a readable file has that name
+			if !slices.Contains(result.Unverifiable, check.name) {
+				result.Unverifiable = append(result.Unverifiable, check.name)
+			}
+			continue
+		}
+		result.Checked++
+		if err != nil {
+			result.Missing = append(result.Missing, ManifestMissing{Name: check.name, Error: err.Error()})
+			continue
+		}
+		if actual := hashes[check.algorithm]; actual != check.expected {
+			result.Mismatched = append(result.Mismatched, ManifestMismatch{
+				Name:      check.name,
+				Algorithm: check.algorithm,
+				Expected:  check.expected,
+				Actual:    actual,
+			})
+			continue
+		}
+		result.Matched++
+	}
+	result.Valid = result.Matched == result.Checked
+	return jsonResult(result)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHashBatchVerifyManifestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "release.tar.gz")
+	if err := os.WriteFile(file, []byte("release contents"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		format     string
+		algorithms []interface{}
+	}{
+		{format: "gnu", algorithms: []interface{}{"sha256"}},
+		{format: "bsd", algorithms: []interface{}{"sha256", "sha512"}},
+		{format: "json", algorithms: []interface{}{"sha256", "blake3"}},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			result, err := runHashBatch(map[string]interface{}{
+				"texts":      []interface{}{"hello", "world"},
+				"paths":      []interface{}{file},
+				"algorithms": tt.algorithms,
+				"format":     tt.format,
+			})
+			manifest := resultText(t, result, err)
+
+			result, err = runVerifyManifest(map[string]interface{}{"manifest": manifest})
+			var got ManifestVerification
+			resultJSON(t, result, err, &got)
+			checks := len(tt.algorithms)
+			if !got.Valid || got.Checked != checks || got.Matched != checks || len(got.Missing) != 0 {
+				t.Errorf("verify_manifest() = %+v, want %d valid checks", got, checks)
+			}
+			if want := []string{"text[0]", "text[1]"}; !reflect.DeepEqual(got.Unverifiable, want) {
+				t.Errorf("unverifiable = %v, want %v", got.Unverifiable, want)
+			}
+
+			// a changed file still fails the manifest
+			if err := os.WriteFile(file, []byte("tampered"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			defer os.WriteFile(file, []byte("release contents"), 0o644)
+			result, err = runVerifyManifest(map[string]interface{}{"manifest": manifest})
+			got = ManifestVerification{}
+			resultJSON(t, result, err, &got)
+			if got.Valid || len(got.Mismatched) != checks {
+				t.Errorf("verify_manifest() of a changed file = %+v, want %d mismatches", got, checks)
+			}
+		})
+	}
+}
+
+func TestVerifyManifestFileNamedLikeText(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "text[0]"), []byte("not hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// sha256("hello") does not match the file, which is checked like any other
+	result, err := runVerifyManifest(map[string]interface{}{
+		"manifest":       "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824  text[0]\n",
+		"base_directory": dir,
+	})
+	var got ManifestVerification
+	resultJSON(t, result, err, &got)
+	if got.Valid || got.Checked != 1 || len(got.Mismatched) != 1 || len(got.Unverifiable) != 0 {
+		t.Errorf("verify_manifest() = %+v, want one mismatched file", got)
+	}
+}
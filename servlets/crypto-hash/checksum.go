@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/adler32"
+	"hash/crc32"
+	"hash/crc64"
+	"hash/fnv"
+	"math/big"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/zeebo/xxh3"
+)
+
+var ChecksumTool = ToolDescription{
+	Name:        "checksum",
+	Description: "Compute a non-cryptographic checksum of a string or file: CRC32 (IEEE or Castagnoli), CRC64 (ISO or ECMA), Adler-32, FNV-1/FNV-1a, xxHash64 or XXH3. These detect accidental corruption only, use `digest` when integrity must hold against tampering. Either text or path must be provided.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"algorithm"},
+		"properties": withProperties(inputProperties, map[string]interface{}{
+			"algorithm": map[string]interface{}{
+				"type":        "string",
+				"description": "the checksum algorithm. crc32 is the IEEE polynomial used by zip, gzip and PNG, crc32c the Castagnoli polynomial used by iSCSI, ext4 and cloud storage",
+				"enum":        checksumAlgorithms,
+			},
+			"output_encoding": map[string]interface{}{
+				"type":        "string",
+				"description": "hex, or the unsigned decimal value (default: hex)",
+				"enum":        []string{"hex", "decimal"},
+				"default":     "hex",
+			},
+		}),
+	},
+}
+
+var checksumAlgorithms = []string{
+	"crc32", "crc32c", "crc64-iso", "crc64-ecma", "adler32",
+	"fnv32", "fnv32a", "fnv64", "fnv64a", "fnv128", "fnv128a",
+	"xxh64", "xxh3-64", "xxh3-128",
+}
+
+// xxh3Digest128 adapts xxh3 to hash.Hash with its 128 bit variant.
+type xxh3Digest128 struct {
+	*xxh3.Hasher
+}
+
+func (x xxh3Digest128) Size() int {
+	return 16
+}
+
+func (x xxh3Digest128) Sum(b []byte) []byte {
+	sum := x.Sum128().Bytes()
+	return append(b, sum[:]...)
+}
+
+func newChecksum(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case "crc32":
+		return crc32.NewIEEE(), nil
+	case "crc32c":
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli)), nil
+	case "crc64-iso":
+		return crc64.New(crc64.MakeTable(crc64.ISO)), nil
+	case "crc64-ecma":
+		return crc64.New(crc64.MakeTable(crc64.ECMA)), nil
+	case "adler32":
+		return adler32.New(), nil
+	case "fnv32":
+		return fnv.New32(), nil
+	case "fnv32a":
+		return fnv.New32a(), nil
+	case "fnv64":
+		return fnv.New64(), nil
+	case "fnv64a":
+		return fnv.New64a(), nil
+	case "fnv128":
+		return fnv.New128(), nil
+	case "fnv128a":
+		return fnv.New128a(), nil
+	case "xxh64":
+		return xxhash.New(), nil
+	case "xxh3-64":
+		return xxh3.New(), nil
+	case "xxh3-128":
+		return xxh3Digest128{xxh3.New()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, expected one of: %s", algorithm, strings.Join(checksumAlgorithms, ", "))
+	}
+}
+
+func runChecksum(args map[string]interface{}) (CallToolResult, error) {
+	algorithm, ok := args["algorithm"].(string)
+	if !ok || algorithm == "" {
+		return CallToolResult{}, errors.New("algorithm must be provided")
+	}
+	h, err := newChecksum(algorithm)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if err := hashInput(h, args); err != nil {
+		return CallToolResult{}, err
+	}
+
+	sum := h.Sum(nil)
+	encoding, _ := args["output_encoding"].(string)
+	switch encoding {
+	case "", "hex":
+		return textResult(hex.EncodeToString(sum)), nil
+	case "decimal":
+		return textResult(new(big.Int).SetBytes(sum).String()), nil
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported output_encoding %q, expected hex or decimal", encoding)
+	}
+}
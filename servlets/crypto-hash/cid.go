@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var IPFSCIDTool = ToolDescription{
+	Name:        "ipfs_cid",
+	Description: "Compute the IPFS content identifier (CID) of a string or file without uploading it. The dag-pb codec matches `ipfs add` for content up to one 256 KiB chunk; the raw codec matches `ipfs add --raw-leaves --cid-version 1` for the same sizes and `ipfs block put` for any block. Either text or path must be provided.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": withProperties(inputProperties, map[string]interface{}{
+			"version": map[string]interface{}{
+				"type":        "integer",
+				"description": "CID version. Version 0 requires the dag-pb codec and sha2-256 (default: 1)",
+				"enum":        []int{0, 1},
+				"default":     1,
+			},
+			"codec": map[string]interface{}{
+				"type":        "string",
+				"description": "raw hashes the bytes as-is, dag-pb wraps them in a UnixFS file node first (default: raw, or dag-pb for version 0)",
+				"enum":        []string{"raw", "dag-pb"},
+			},
+			"hash": map[string]interface{}{
+				"type":        "string",
+				"description": "the multihash function (default: sha2-256)",
+				"enum":        []string{"sha2-256", "sha2-512", "sha3-256", "sha3-512", "blake2b-256", "blake3"},
+				"default":     "sha2-256",
+			},
+			"multibase": map[string]interface{}{
+				"type":        "string",
+				"description": "string encoding of a version 1 CID (default: base32)",
+				"enum":        []string{"base32", "base58btc"},
+				"default":     "base32",
+			},
+		}),
+	},
+}
+
+// ContentID is returned by ipfs_cid.
+type ContentID struct {
+	CID       string `json:"cid"`
+	Version   int    `json:"version"`
+	Codec     string `json:"codec"`
+	Hash      string `json:"hash"`
+	Multihash string `json:"multihash"`
+	Size      int    `json:"size"`
+}
+
+// unixfsChunkSize is the default chunk size of `ipfs add`. Larger files are
+// split into a DAG of chunks, which is not reproduced here.
+const unixfsChunkSize = 256 * 1024
+
+var multicodecs = map[string]uint64{
+	"raw":    0x55,
+	"dag-pb": 0x70,
+}
+
+var multihashes = map[string]struct {
+	code   uint64
+	digest string
+}{
+	"sha2-256":    {0x12, "sha256"},
+	"sha2-512":    {0x13, "sha512"},
+	"sha3-256":    {0x16, "sha3-256"},
+	"sha3-512":    {0x14, "sha3-512"},
+	"blake2b-256": {0xb220, "blake2b-256"},
+	"blake3":      {0x1e, "blake3"},
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Encode encodes b with the Bitcoin alphabet, keeping leading zero
+// bytes as '1'.
+func base58Encode(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, '1')
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// protoBytes appends a length-delimited protobuf field.
+func protoBytes(b []byte, field int, value []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field<<3|2))
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+// protoVarint appends a varint protobuf field.
+func protoVarint(b []byte, field int, value uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(field<<3))
+	return binary.AppendUvarint(b, value)
+}
+
+// unixfsFileNode returns the dag-pb encoding of a single UnixFS file node
+// holding data, as built by `ipfs add` for content that fits one chunk.
+func unixfsFileNode(data []byte) []byte {
+	var unixfs []byte
+	unixfs = protoVarint(unixfs, 1, 2) // Type: File
+	if len(data) > 0 {
+		unixfs = protoBytes(unixfs, 2, data)
+	}
+	unixfs = protoVarint(unixfs, 3, uint64(len(data)))
+	return protoBytes(nil, 1, unixfs)
+}
+
+func runIPFSCID(args map[string]interface{}) (CallToolResult, error) {
+	version, err := intArg(args, "version", 1, 0, 1)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	codec := "raw"
+	if version == 0 {
+		codec = "dag-pb"
+	}
+	if codecVal, exists := args["codec"].(string); exists && codecVal != "" {
+		codec = codecVal
+	}
+	codecCode, ok := multicodecs[codec]
+	if !ok {
+		return CallToolResult{}, fmt.Errorf("unsupported codec %q, expected raw or dag-pb", codec)
+	}
+	hashName := "sha2-256"
+	if hashVal, exists := args["hash"].(string); exists && hashVal != "" {
+		hashName = hashVal
+	}
+	mh, ok := multihashes[hashName]
+	if !ok {
+		return CallToolResult{}, fmt.Errorf("unsupported hash %q", hashName)
+	}
+	if version == 0 && (codec != "dag-pb" || hashName != "sha2-256") {
+		return CallToolResult{}, errors.New("CID version 0 only supports the dag-pb codec with sha2-256")
+	}
+
+	data, err := readInput(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	block := data
+	if codec == "dag-pb" {
+		if len(data) > unixfsChunkSize {
+			return CallToolResult{}, fmt.Errorf("dag-pb CIDs are only supported for content up to %d bytes, use the raw codec for the block CID", unixfsChunkSize)
+		}
+		block = unixfsFileNode(data)
+	}
+
+	h, err := newDigest(mh.digest, 0)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	h.Write(block)
+	digest := h.Sum(nil)
+
+	multihash := binary.AppendUvarint(nil, mh.code)
+	multihash = binary.AppendUvarint(multihash, uint64(len(digest)))
+	multihash = append(multihash, digest...)
+
+	result := ContentID{
+		Version:   version,
+		Codec:     codec,
+		Hash:      hashName,
+		Multihash: hex.EncodeToString(multihash),
+		Size:      len(data),
+	}
+	if version == 0 {
+		result.CID = base58Encode(multihash)
+		return jsonResult(result)
+	}
+
+	cid := binary.AppendUvarint(nil, 1)
+	cid = binary.AppendUvarint(cid, codecCode)
+	cid = append(cid, multihash...)
+
+	multibase, _ := args["multibase"].(string)
+	switch multibase {
+	case "", "base32":
+		result.CID = "b" + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(cid))
+	case "base58btc":
+		result.CID = "z" + base58Encode(cid)
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported multibase %q, expected base32 or base58btc", multibase)
+	}
+	return jsonResult(result)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestIPFSCID(t *testing.T) {
+	// the dag-pb CIDs match `ipfs add --only-hash` (with --cid-version 1 for
+	// version 1) and the raw ones `ipfs add --only-hash --raw-leaves
+	// --cid-version 1`
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{
+			name: "empty file v0",
+			args: map[string]interface{}{"text": "", "version": float64(0)},
+			want: "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH",
+		},
+		{
+			name: "hello world v0",
+			args: map[string]interface{}{"text": "hello world\n", "version": float64(0)},
+			want: "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o",
+		},
+		{
+			name: "empty file v1 dag-pb",
+			args: map[string]interface{}{"text": "", "codec": "dag-pb"},
+			want: "bafybeif7ztnhq65lumvvtr4ekcwd2ifwgm3awq4zfr3srh462rwyinlb4y",
+		},
+		{
+			name: "hello world v1 dag-pb",
+			args: map[string]interface{}{"text": "hello world\n", "codec": "dag-pb"},
+			want: "bafybeicg2rebjoofv4kbyovkw7af3rpiitvnl6i7ckcywaq6xjcxnc2mby",
+		},
+		{
+			name: "empty file v1 raw",
+			args: map[string]interface{}{"text": ""},
+			want: "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+		},
+		{
+			name: "hello world v1 raw",
+			args: map[string]interface{}{"text": "aGVsbG8gd29ybGQK", "encoding": "base64"},
+			want: "bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4",
+		},
+		{
+			name: "hello world v1 raw base58btc",
+			args: map[string]interface{}{"text": "hello world\n", "multibase": "base58btc"},
+			want: "zb2rhi36Gc9GJWijLEL6zW45MBux5FcFv5gJmjXA7VAMozEXY",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runIPFSCID(tt.args)
+
+			var got ContentID
+			resultJSON(t, result, err, &got)
+			if got.CID != tt.want {
+				t.Errorf("ipfs_cid() = %s, want %s", got.CID, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPFSCIDRejectsV0Raw(t *testing.T) {
+	if _, err := runIPFSCID(map[string]interface{}{"text": "hello", "version": float64(0), "codec": "raw"}); err == nil {
+		t.Error("ipfs_cid() accepted a version 0 raw CID")
+	}
+}
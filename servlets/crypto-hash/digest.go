@@ -0,0 +1,238 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+	"golang.org/x/crypto/sha3"
+	"lukechampine.com/blake3"
+)
+
+var DigestTool = ToolDescription{
+	Name:        "digest",
+	Description: "Hash a string or file with any SHA-2, SHA-3, SHAKE, BLAKE2 or BLAKE3 algorithm and return the digest as hex, base64, base64url or base32. Can also format the result as a Subresource Integrity (SRI) value or an OCI/Docker-style digest. Either text or path must be provided.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"algorithm"},
+		"properties": withProperties(inputProperties, map[string]interface{}{
+			"algorithm": map[string]interface{}{
+				"type":        "string",
+				"description": "the hash algorithm to use",
+				"enum":        digestAlgorithms,
+			},
+			"length": map[string]interface{}{
+				"type":        "integer",
+				"description": "output length in bytes for shake128 (default: 32), shake256 (default: 64), blake2b (1-64, default: 64) and blake3 (default: 32). Ignored by fixed-size algorithms",
+				"minimum":     1,
+				"maximum":     1024,
+			},
+			"output_encoding": map[string]interface{}{
+				"type":        "string",
+				"description": "encoding of the digest (default: hex). base64url is unpadded",
+				"enum":        []string{"hex", "base64", "base64url", "base32"},
+				"default":     "hex",
+			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"description": "plain returns the encoded digest only, sri returns `<algorithm>-<base64>` for sha256/sha384/sha512, oci returns `<algorithm>:<hex>` for sha256/sha512 as used by Docker and OCI registries. Both ignore output_encoding (default: plain)",
+				"enum":        []string{"plain", "sri", "oci"},
+				"default":     "plain",
+			},
+		}),
+	},
+}
+
+var digestAlgorithms = []string{
+	"md5",
+	"sha1",
+	"sha224",
+	"sha256",
+	"sha384",
+	"sha512",
+	"sha512-224",
+	"sha512-256",
+	"sha3-224",
+	"sha3-256",
+	"sha3-384",
+	"sha3-512",
+	"shake128",
+	"shake256",
+	"blake2b",
+	"blake2b-256",
+	"blake2b-384",
+	"blake2b-512",
+	"blake2s-256",
+	"blake3",
+}
+
+func runDigest(args map[string]interface{}) (CallToolResult, error) {
+	algorithm, ok := args["algorithm"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("algorithm must be provided")
+	}
+	algorithm = strings.ToLower(algorithm)
+
+	length := 0
+	if lengthVal, exists := args["length"].(float64); exists {
+		length = int(lengthVal)
+		if length < 1 || length > 1024 {
+			return CallToolResult{}, errors.New("length must be between 1 and 1024")
+		}
+	}
+
+	encoding := "hex"
+	if encodingVal, exists := args["output_encoding"].(string); exists && encodingVal != "" {
+		encoding = encodingVal
+	}
+
+	format := "plain"
+	if formatVal, exists := args["format"].(string); exists && formatVal != "" {
+		format = formatVal
+	}
+
+	h, err := newDigest(algorithm, length)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if err := hashInput(h, args); err != nil {
+		return CallToolResult{}, err
+	}
+
+	digest, err := formatDigest(algorithm, h.Sum(nil), encoding, format)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	return textResult(digest), nil
+}
+
+// newDigest returns a hash.Hash for the named algorithm. length is only used
+// by the variable-length algorithms; zero selects their default size.
+func newDigest(algorithm string, length int) (hash.Hash, error) {
+	switch algorithm {
+	case "md5":
+		return md5.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "sha224":
+		return sha256.New224(), nil
+	case "sha256":
+		return sha256.New(), nil
+	case "sha384":
+		return sha512.New384(), nil
+	case "sha512":
+		return sha512.New(), nil
+	case "sha512-224":
+		return sha512.New512_224(), nil
+	case "sha512-256":
+		return sha512.New512_256(), nil
+	case "sha3-224":
+		return sha3.New224(), nil
+	case "sha3-256":
+		return sha3.New256(), nil
+	case "sha3-384":
+		return sha3.New384(), nil
+	case "sha3-512":
+		return sha3.New512(), nil
+	case "shake128":
+		if length == 0 {
+			length = 32
+		}
+		return &shakeDigest{ShakeHash: sha3.NewShake128(), size: length}, nil
+	case "shake256":
+		if length == 0 {
+			length = 64
+		}
+		return &shakeDigest{ShakeHash: sha3.NewShake256(), size: length}, nil
+	case "blake2b":
+		if length == 0 {
+			length = blake2b.Size
+		}
+		if length > blake2b.Size {
+			return nil, fmt.Errorf("blake2b length must be between 1 and %d", blake2b.Size)
+		}
+		return blake2b.New(length, nil)
+	case "blake2b-256":
+		return blake2b.New256(nil)
+	case "blake2b-384":
+		return blake2b.New384(nil)
+	case "blake2b-512":
+		return blake2b.New512(nil)
+	case "blake2s", "blake2s-256":
+		return blake2s.New256(nil)
+	case "blake3":
+		if length == 0 {
+			length = 32
+		}
+		return blake3.New(length, nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, expected one of: %s", algorithm, strings.Join(digestAlgorithms, ", "))
+	}
+}
+
+// shakeDigest adapts a SHAKE extendable-output function to hash.Hash so that
+// Sum returns exactly size bytes.
+type shakeDigest struct {
+	sha3.ShakeHash
+	size int
+}
+
+func (s *shakeDigest) Size() int {
+	return s.size
+}
+
+func (s *shakeDigest) Sum(b []byte) []byte {
+	out := make([]byte, s.size)
+	s.ShakeHash.Clone().Read(out)
+	return append(b, out...)
+}
+
+func encodeDigest(sum []byte, encoding string) (string, error) {
+	switch encoding {
+	case "hex":
+		return hex.EncodeToString(sum), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(sum), nil
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString(sum), nil
+	case "base32":
+		return base32.StdEncoding.EncodeToString(sum), nil
+	default:
+		return "", fmt.Errorf("unsupported encoding %q, expected hex, base64, base64url or base32", encoding)
+	}
+}
+
+func formatDigest(algorithm string, sum []byte, encoding, format string) (string, error) {
+	switch format {
+	case "plain":
+		return encodeDigest(sum, encoding)
+	case "sri":
+		switch algorithm {
+		case "sha256", "sha384", "sha512":
+		default:
+			return "", errors.New("sri format requires sha256, sha384 or sha512")
+		}
+		return algorithm + "-" + base64.StdEncoding.EncodeToString(sum), nil
+	case "oci":
+		// the OCI image spec registers only these two, with lowercase hex
+		switch algorithm {
+		case "sha256", "sha512":
+		default:
+			return "", errors.New("oci format requires sha256 or sha512")
+		}
+		return algorithm + ":" + hex.EncodeToString(sum), nil
+	default:
+		return "", fmt.Errorf("unsupported format %q, expected plain, sri or oci", format)
+	}
+}
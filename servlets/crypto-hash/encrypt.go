@@ -0,0 +1,294 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+var encryptionKeyProperties = map[string]interface{}{
+	"key": map[string]interface{}{
+		"type":        "string",
+		"description": "a raw 32 byte key, encoded according to `key_encoding`. Either key or passphrase must be provided",
+	},
+	"key_encoding": map[string]interface{}{
+		"type":        "string",
+		"description": "how `key` is encoded (default: base64)",
+		"enum":        []string{"hex", "base64"},
+		"default":     "base64",
+	},
+	"passphrase": map[string]interface{}{
+		"type":        "string",
+		"description": "a passphrase to derive the key from. The KDF and its parameters are recorded in the envelope",
+	},
+	"aad": map[string]interface{}{
+		"type":        "string",
+		"description": "optional additional authenticated data. It is not encrypted but must match on decryption",
+	},
+}
+
+var (
+	EncryptTool = ToolDescription{
+		Name:        "encrypt",
+		Description: "Encrypt a string or file with AES-256-GCM or XChaCha20-Poly1305. Returns a JSON envelope holding the algorithm, KDF parameters, salt, nonce and ciphertext (base64) that `decrypt` accepts as-is. Either text or path must be provided.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": withProperties(inputProperties, encryptionKeyProperties, map[string]interface{}{
+				"algorithm": map[string]interface{}{
+					"type":        "string",
+					"description": "the AEAD cipher (default: aes-256-gcm)",
+					"enum":        []string{"aes-256-gcm", "xchacha20-poly1305"},
+					"default":     "aes-256-gcm",
+				},
+				"kdf": map[string]interface{}{
+					"type":        "string",
+					"description": "how to derive the key from `passphrase`. Use argon2id for human passphrases and hkdf-sha256 for high-entropy secrets (default: argon2id)",
+					"enum":        []string{"argon2id", "hkdf-sha256"},
+					"default":     "argon2id",
+				},
+			}),
+		},
+	}
+	DecryptTool = ToolDescription{
+		Name:        "decrypt",
+		Description: "Decrypt an envelope produced by `encrypt` using the same key or passphrase.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"envelope"},
+			"properties": withProperties(encryptionKeyProperties, map[string]interface{}{
+				"envelope": map[string]interface{}{
+					"type":        "string",
+					"description": "the JSON envelope returned by `encrypt`",
+				},
+				"output_encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "encoding of the decrypted plaintext. Use hex or base64 for binary data (default: utf8)",
+					"enum":        []string{"utf8", "hex", "base64"},
+					"default":     "utf8",
+				},
+			}),
+		},
+	}
+)
+
+// Envelope is the self-describing ciphertext format used by encrypt and
+// decrypt. Binary fields are standard base64.
+type Envelope struct {
+	Version    int        `json:"v"`
+	Algorithm  string     `json:"alg"`
+	KDF        string     `json:"kdf"`
+	KDFParams  *KDFParams `json:"kdf_params,omitempty"`
+	Salt       string     `json:"salt,omitempty"`
+	Nonce      string     `json:"nonce"`
+	Ciphertext string     `json:"ciphertext"`
+}
+
+// KDFParams records the argon2id cost parameters of an envelope.
+type KDFParams struct {
+	Memory      uint32 `json:"m"`
+	Iterations  uint32 `json:"t"`
+	Parallelism uint8  `json:"p"`
+}
+
+const (
+	envelopeVersion  = 1
+	envelopeKeySize  = 32
+	envelopeHKDFInfo = "crypto-hash envelope v1"
+)
+
+var defaultEnvelopeKDFParams = KDFParams{Memory: 19456, Iterations: 2, Parallelism: 1}
+
+func newAEAD(algorithm string, key []byte) (cipher.AEAD, error) {
+	switch algorithm {
+	case "aes-256-gcm":
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case "xchacha20-poly1305":
+		return chacha20poly1305.NewX(key)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, expected aes-256-gcm or xchacha20-poly1305", algorithm)
+	}
+}
+
+// envelopeKey returns the encryption key for env, deriving it from the
+// passphrase when the envelope uses a KDF.
+func envelopeKey(args map[string]interface{}, env Envelope) ([]byte, error) {
+	key, hasKey := args["key"].(string)
+	passphrase, hasPassphrase := args["passphrase"].(string)
+
+	if env.KDF == "none" {
+		if !hasKey {
+			return nil, errors.New("key must be provided")
+		}
+		encoding := "base64"
+		if encodingVal, exists := args["key_encoding"].(string); exists && encodingVal != "" {
+			encoding = encodingVal
+		}
+		raw, err := decodeInput(key, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key: %w", err)
+		}
+		if len(raw) != envelopeKeySize {
+			return nil, fmt.Errorf("key must be %d bytes, got %d", envelopeKeySize, len(raw))
+		}
+		return raw, nil
+	}
+
+	if !hasPassphrase || passphrase == "" {
+		return nil, errors.New("passphrase must be provided")
+	}
+	salt, err := base64.StdEncoding.DecodeString(env.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+
+	switch env.KDF {
+	case "argon2id":
+		if env.KDFParams == nil {
+			return nil, errors.New("envelope is missing kdf_params")
+		}
+		p := env.KDFParams
+		if p.Memory < 8 || p.Memory > 1048576 || p.Iterations < 1 || p.Iterations > 100 || p.Parallelism < 1 {
+			return nil, errors.New("envelope kdf_params are out of range")
+		}
+		return argon2.IDKey([]byte(passphrase), salt, p.Iterations, p.Memory, p.Parallelism, envelopeKeySize), nil
+	case "hkdf-sha256":
+		out := make([]byte, envelopeKeySize)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(passphrase), salt, []byte(envelopeHKDFInfo)), out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported kdf %q", env.KDF)
+	}
+}
+
+func runEncrypt(args map[string]interface{}) (CallToolResult, error) {
+	env := Envelope{Version: envelopeVersion, Algorithm: "aes-256-gcm", KDF: "none"}
+	if algorithmVal, exists := args["algorithm"].(string); exists && algorithmVal != "" {
+		env.Algorithm = algorithmVal
+	}
+
+	_, hasKey := args["key"].(string)
+	_, hasPassphrase := args["passphrase"].(string)
+	switch {
+	case hasKey && hasPassphrase:
+		return CallToolResult{}, errors.New("provide either key or passphrase, not both")
+	case hasPassphrase:
+		env.KDF = "argon2id"
+		if kdfVal, exists := args["kdf"].(string); exists && kdfVal != "" {
+			env.KDF = kdfVal
+		}
+		if env.KDF == "argon2id" {
+			params := defaultEnvelopeKDFParams
+			env.KDFParams = &params
+		}
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return CallToolResult{}, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		env.Salt = base64.StdEncoding.EncodeToString(salt)
+	case !hasKey:
+		return CallToolResult{}, errors.New("either key or passphrase must be provided")
+	}
+
+	key, err := envelopeKey(args, env)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	aead, err := newAEAD(env.Algorithm, key)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	plaintext, err := readInput(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return CallToolResult{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	aad, _ := args["aad"].(string)
+
+	env.Nonce = base64.StdEncoding.EncodeToString(nonce)
+	env.Ciphertext = base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plaintext, []byte(aad)))
+
+	out, err := json.Marshal(env)
+	if err != nil {
+		return CallToolResult{}, fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	return textResult(string(out)), nil
+}
+
+func runDecrypt(args map[string]interface{}) (CallToolResult, error) {
+	envelopeArg, err := objectArg(args, "envelope")
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if envelopeArg == nil {
+		return CallToolResult{}, errors.New("envelope must be provided")
+	}
+	raw, _ := json.Marshal(envelopeArg)
+	var env Envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return CallToolResult{}, fmt.Errorf("invalid envelope: %w", err)
+	}
+	if env.Version != envelopeVersion {
+		return CallToolResult{}, fmt.Errorf("unsupported envelope version %d", env.Version)
+	}
+
+	key, err := envelopeKey(args, env)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	aead, err := newAEAD(env.Algorithm, key)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(env.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return CallToolResult{}, errors.New("invalid nonce")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(env.Ciphertext)
+	if err != nil {
+		return CallToolResult{}, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	aad, _ := args["aad"].(string)
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(aad))
+	if err != nil {
+		return CallToolResult{}, errors.New("decryption failed: wrong key or passphrase, mismatched aad, or tampered ciphertext")
+	}
+
+	encoding, _ := args["output_encoding"].(string)
+	switch encoding {
+	case "", "utf8":
+		if !utf8.Valid(plaintext) {
+			return CallToolResult{}, errors.New("plaintext is not valid UTF-8, use output_encoding hex or base64")
+		}
+		return textResult(string(plaintext)), nil
+	case "hex":
+		return textResult(hex.EncodeToString(plaintext)), nil
+	case "base64":
+		return textResult(base64.StdEncoding.EncodeToString(plaintext)), nil
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported output_encoding %q, expected utf8, hex or base64", encoding)
+	}
+}
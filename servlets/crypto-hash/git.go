@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var GitObjectIDTool = ToolDescription{
+	Name:        "git_object_id",
+	Description: "Compute the ID Git assigns to a blob or tree, as `git hash-object` and `git mktree` would, without a repository. A blob ID can be compared with the sha of a file returned by the GitHub API. For a blob, either text or path must be provided; for a tree, provide entries.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": withProperties(inputProperties, map[string]interface{}{
+			"type": map[string]interface{}{
+				"type":        "string",
+				"description": "the object type (default: blob)",
+				"enum":        []string{"blob", "tree"},
+				"default":     "blob",
+			},
+			"algorithm": map[string]interface{}{
+				"type":        "string",
+				"description": "the repository's object format (default: sha1)",
+				"enum":        []string{"sha1", "sha256"},
+				"default":     "sha1",
+			},
+			"entries": map[string]interface{}{
+				"type":        "array",
+				"description": "tree entries, in any order",
+				"items": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"mode", "name", "sha"},
+					"properties": map[string]interface{}{
+						"mode": map[string]interface{}{
+							"type":        "string",
+							"description": "100644 (file), 100755 (executable), 120000 (symlink), 040000 (tree) or 160000 (submodule)",
+						},
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "the entry name, without any directory",
+						},
+						"sha": map[string]interface{}{
+							"type":        "string",
+							"description": "the hex object ID of the entry",
+						},
+					},
+				},
+			},
+		}),
+	},
+}
+
+// GitObjectID is returned by git_object_id.
+type GitObjectID struct {
+	SHA  string `json:"sha"`
+	Type string `json:"type"`
+	Size int    `json:"size"`
+}
+
+type gitTreeEntry struct {
+	mode string
+	name string
+	id   []byte
+}
+
+// gitTree serialises entries in Git's tree order, where a subtree sorts as
+// if its name ended in a slash.
+func gitTree(entries []gitTreeEntry) []byte {
+	sortKey := func(e gitTreeEntry) string {
+		if e.mode == "40000" {
+			return e.name + "/"
+		}
+		return e.name
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return sortKey(entries[i]) < sortKey(entries[j])
+	})
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "%s %s\x00", e.mode, e.name)
+		buf.Write(e.id)
+	}
+	return buf.Bytes()
+}
+
+func parseGitTreeEntries(args map[string]interface{}, idSize int) ([]gitTreeEntry, error) {
+	list, ok := args["entries"].([]interface{})
+	if !ok {
+		return nil, errors.New("entries must be provided for a tree")
+	}
+
+	entries := make([]gitTreeEntry, 0, len(list))
+	seen := map[string]bool{}
+	for i, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("entries[%d] must be an object", i)
+		}
+		mode, _ := obj["mode"].(string)
+		name, _ := obj["name"].(string)
+		sha, _ := obj["sha"].(string)
+
+		// git writes tree modes without a leading zero
+		mode = strings.TrimLeft(mode, "0")
+		switch mode {
+		case "100644", "100755", "120000", "40000", "160000":
+		default:
+			return nil, fmt.Errorf("entries[%d] has unsupported mode %q", i, obj["mode"])
+		}
+		if name == "" || strings.ContainsAny(name, "/\x00") || name == "." || name == ".." {
+			return nil, fmt.Errorf("entries[%d] has invalid name %q", i, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate tree entry %q", name)
+		}
+		seen[name] = true
+		id, err := hex.DecodeString(sha)
+		if err != nil || len(id) != idSize {
+			return nil, fmt.Errorf("entries[%d] sha must be %d hex characters", i, idSize*2)
+		}
+		entries = append(entries, gitTreeEntry{mode: mode, name: name, id: id})
+	}
+	return entries, nil
+}
+
+func runGitObjectID(args map[string]interface{}) (CallToolResult, error) {
+	objectType := "blob"
+	if typeVal, exists := args["type"].(string); exists && typeVal != "" {
+		objectType = typeVal
+	}
+	algorithm := "sha1"
+	if algorithmVal, exists := args["algorithm"].(string); exists && algorithmVal != "" {
+		algorithm = algorithmVal
+	}
+	if algorithm != "sha1" && algorithm != "sha256" {
+		return CallToolResult{}, fmt.Errorf("unsupported algorithm %q, expected sha1 or sha256", algorithm)
+	}
+	h, _ := newDigest(algorithm, 0)
+
+	var content []byte
+	var err error
+	switch objectType {
+	case "blob":
+		content, err = readInput(args)
+	case "tree":
+		var entries []gitTreeEntry
+		entries, err = parseGitTreeEntries(args, h.Size())
+		content = gitTree(entries)
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported type %q, expected blob or tree", objectType)
+	}
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	fmt.Fprintf(h, "%s %d\x00", objectType, len(content))
+	h.Write(content)
+	return jsonResult(GitObjectID{
+		SHA:  hex.EncodeToString(h.Sum(nil)),
+		Type: objectType,
+		Size: len(content),
+	})
+}
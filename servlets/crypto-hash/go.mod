@@ -2,6 +2,16 @@ module crypto-hash
 
 go 1.22.1
 
-require github.com/extism/go-pdk v1.0.5
+require (
+	github.com/cespare/xxhash/v2 v2.3.0
+	github.com/extism/go-pdk v1.0.5
+	github.com/sethvargo/go-diceware v0.3.0
+	github.com/zeebo/xxh3 v1.0.2
+	golang.org/x/crypto v0.31.0
+	lukechampine.com/blake3 v1.4.1
+)
 
-require golang.org/x/crypto v0.31.0 // indirect
+require (
+	github.com/klauspost/cpuid/v2 v2.0.9 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+)
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var hmacProperties = map[string]interface{}{
+	"key": map[string]interface{}{
+		"type":        "string",
+		"description": "the secret key, interpreted according to `key_encoding`",
+	},
+	"key_encoding": map[string]interface{}{
+		"type":        "string",
+		"description": "how `key` is encoded (default: utf8)",
+		"enum":        []string{"utf8", "hex", "base64"},
+		"default":     "utf8",
+	},
+	"algorithm": map[string]interface{}{
+		"type":        "string",
+		"description": "the hash algorithm to use, ignored when a preset is selected (default: sha256)",
+		"enum":        hmacAlgorithms,
+		"default":     "sha256",
+	},
+	"signature_encoding": map[string]interface{}{
+		"type":        "string",
+		"description": "encoding of the signature, ignored when a preset is selected (default: hex)",
+		"enum":        []string{"hex", "base64", "base64url"},
+		"default":     "hex",
+	},
+	"preset": map[string]interface{}{
+		"type":        "string",
+		"description": "webhook signing scheme. github signs the body for X-Hub-Signature-256, slack signs `v0:<timestamp>:<body>` for X-Slack-Signature, stripe signs `<timestamp>.<body>` for Stripe-Signature. The body is taken from text or path",
+		"enum":        []string{"github", "slack", "stripe"},
+	},
+	"timestamp": map[string]interface{}{
+		"type":        "string",
+		"description": "unix timestamp for the slack and stripe presets (X-Slack-Request-Timestamp, or the `t=` value of Stripe-Signature). Defaults to the current time when signing",
+	},
+}
+
+var (
+	HMACSignTool = ToolDescription{
+		Name:        "hmac_sign",
+		Description: "Compute an HMAC signature of a string or file using SHA-1, SHA-256, SHA-384 or SHA-512. With a preset, produces the signature header value GitHub, Slack or Stripe would send for the given webhook body. Either text or path must be provided.",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"required":   []string{"key"},
+			"properties": withProperties(inputProperties, hmacProperties),
+		},
+	}
+	HMACVerifyTool = ToolDescription{
+		Name:        "hmac_verify",
+		Description: "Verify an HMAC signature of a string or file using a constant-time comparison. With a preset, pass the webhook body and the raw GitHub X-Hub-Signature-256, Slack X-Slack-Signature or Stripe Stripe-Signature header value as `signature`. Either text or path must be provided.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"key", "signature"},
+			"properties": withProperties(inputProperties, hmacProperties, map[string]interface{}{
+				"signature": map[string]interface{}{
+					"type":        "string",
+					"description": "the signature to check, or the raw signature header value when a preset is selected",
+				},
+				"tolerance": map[string]interface{}{
+					"type":        "integer",
+					"description": "maximum age in seconds of the slack or stripe timestamp. 0 disables the check (default: 0)",
+					"minimum":     0,
+					"default":     0,
+				},
+			}),
+		},
+	}
+)
+
+var hmacAlgorithms = []string{"sha1", "sha256", "sha384", "sha512"}
+
+// HMACPresetSignature is returned by hmac_sign when a preset is selected.
+type HMACPresetSignature struct {
+	Header    string `json:"header"`
+	Signature string `json:"signature"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// HMACVerification is returned by hmac_verify.
+type HMACVerification struct {
+	Valid      bool   `json:"valid"`
+	Algorithm  string `json:"algorithm"`
+	Preset     string `json:"preset,omitempty"`
+	Timestamp  string `json:"timestamp,omitempty"`
+	AgeSeconds *int64 `json:"age_seconds,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+func runHMACSign(args map[string]interface{}) (CallToolResult, error) {
+	key, err := hmacKey(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	preset, _ := args["preset"].(string)
+	if preset == "" {
+		algorithm, err := hmacAlgorithm(args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+
+		mac, err := computeHMAC(algorithm, key, "", args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+
+		encoding := "hex"
+		if encodingVal, exists := args["signature_encoding"].(string); exists && encodingVal != "" {
+			encoding = encodingVal
+		}
+		signature, err := encodeDigest(mac, encoding)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		return textResult(signature), nil
+	}
+
+	timestamp, ok := timestampArg(args)
+	if !ok {
+		timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+	}
+
+	var out HMACPresetSignature
+	switch preset {
+	case "github":
+		mac, err := computeHMAC("sha256", key, "", args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		out = HMACPresetSignature{
+			Header:    "X-Hub-Signature-256",
+			Signature: "sha256=" + hex.EncodeToString(mac),
+		}
+	case "slack":
+		mac, err := computeHMAC("sha256", key, "v0:"+timestamp+":", args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		out = HMACPresetSignature{
+			Header:    "X-Slack-Signature",
+			Signature: "v0=" + hex.EncodeToString(mac),
+			Timestamp: timestamp,
+		}
+	case "stripe":
+		mac, err := computeHMAC("sha256", key, timestamp+".", args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		out = HMACPresetSignature{
+			Header:    "Stripe-Signature",
+			Signature: "t=" + timestamp + ",v1=" + hex.EncodeToString(mac),
+			Timestamp: timestamp,
+		}
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported preset %q, expected github, slack or stripe", preset)
+	}
+
+	return jsonResult(out)
+}
+
+func runHMACVerify(args map[string]interface{}) (CallToolResult, error) {
+	key, err := hmacKey(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	signature, ok := args["signature"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("signature must be provided")
+	}
+	signature = strings.TrimSpace(signature)
+
+	tolerance := int64(0)
+	if toleranceVal, exists := args["tolerance"].(float64); exists {
+		tolerance = int64(toleranceVal)
+		if tolerance < 0 {
+			return CallToolResult{}, errors.New("tolerance must not be negative")
+		}
+	}
+
+	preset, _ := args["preset"].(string)
+	result := HMACVerification{Algorithm: "sha256", Preset: preset}
+
+	switch preset {
+	case "":
+		algorithm, err := hmacAlgorithm(args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		result.Algorithm = algorithm
+
+		encoding := "hex"
+		if encodingVal, exists := args["signature_encoding"].(string); exists && encodingVal != "" {
+			encoding = encodingVal
+		}
+		expected, err := decodeSignature(signature, encoding)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+
+		mac, err := computeHMAC(algorithm, key, "", args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		result.Valid = hmac.Equal(mac, expected)
+
+	case "github":
+		algorithm, sig, found := strings.Cut(signature, "=")
+		if !found || (algorithm != "sha256" && algorithm != "sha1") {
+			return CallToolResult{}, errors.New("github signature must look like sha256=<hex> or sha1=<hex>")
+		}
+		result.Algorithm = algorithm
+
+		expected, err := decodeSignature(sig, "hex")
+		if err != nil {
+			return CallToolResult{}, err
+		}
+
+		mac, err := computeHMAC(algorithm, key, "", args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		result.Valid = hmac.Equal(mac, expected)
+
+	case "slack":
+		timestamp, ok := timestampArg(args)
+		if !ok {
+			return CallToolResult{}, errors.New("timestamp (X-Slack-Request-Timestamp) must be provided for the slack preset")
+		}
+		result.Timestamp = timestamp
+
+		version, sig, found := strings.Cut(signature, "=")
+		if !found || version != "v0" {
+			return CallToolResult{}, errors.New("slack signature must look like v0=<hex>")
+		}
+		expected, err := decodeSignature(sig, "hex")
+		if err != nil {
+			return CallToolResult{}, err
+		}
+
+		mac, err := computeHMAC("sha256", key, "v0:"+timestamp+":", args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		result.Valid = hmac.Equal(mac, expected)
+
+	case "stripe":
+		timestamp, candidates := parseStripeSignature(signature)
+		if timestamp == "" || len(candidates) == 0 {
+			return CallToolResult{}, errors.New("stripe signature must look like t=<timestamp>,v1=<hex>")
+		}
+		result.Timestamp = timestamp
+
+		mac, err := computeHMAC("sha256", key, timestamp+".", args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		// Stripe may send several v1 signatures while a secret is being
+		// rolled, any of them matching is enough.
+		for _, candidate := range candidates {
+			expected, err := decodeSignature(candidate, "hex")
+			if err != nil {
+				continue
+			}
+			if hmac.Equal(mac, expected) {
+				result.Valid = true
+			}
+		}
+
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported preset %q, expected github, slack or stripe", preset)
+	}
+
+	if result.Timestamp != "" {
+		ts, err := strconv.ParseInt(result.Timestamp, 10, 64)
+		if err != nil {
+			return CallToolResult{}, fmt.Errorf("invalid timestamp %q: %w", result.Timestamp, err)
+		}
+		age := time.Now().Unix() - ts
+		result.AgeSeconds = &age
+		if !result.Valid {
+			result.Reason = "signature mismatch"
+		} else if tolerance > 0 && (age > tolerance || age < -tolerance) {
+			result.Valid = false
+			result.Reason = fmt.Sprintf("timestamp is outside the %d second tolerance", tolerance)
+		}
+	} else if !result.Valid {
+		result.Reason = "signature mismatch"
+	}
+
+	return jsonResult(result)
+}
+
+func hmacKey(args map[string]interface{}) ([]byte, error) {
+	key, ok := args["key"].(string)
+	if !ok {
+		return nil, errors.New("key must be provided")
+	}
+	encoding, _ := args["key_encoding"].(string)
+	decoded, err := decodeInput(key, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	return decoded, nil
+}
+
+func hmacAlgorithm(args map[string]interface{}) (string, error) {
+	algorithm := "sha256"
+	if algorithmVal, exists := args["algorithm"].(string); exists && algorithmVal != "" {
+		algorithm = strings.ToLower(algorithmVal)
+	}
+	for _, supported := range hmacAlgorithms {
+		if algorithm == supported {
+			return algorithm, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported algorithm %q, expected one of: %s", algorithm, strings.Join(hmacAlgorithms, ", "))
+}
+
+// computeHMAC returns the MAC of prefix followed by the tool input.
+func computeHMAC(algorithm string, key []byte, prefix string, args map[string]interface{}) ([]byte, error) {
+	if _, err := newDigest(algorithm, 0); err != nil {
+		return nil, err
+	}
+	mac := hmac.New(func() hash.Hash {
+		h, _ := newDigest(algorithm, 0)
+		return h
+	}, key)
+
+	mac.Write([]byte(prefix))
+	if err := hashInput(mac, args); err != nil {
+		return nil, err
+	}
+	return mac.Sum(nil), nil
+}
+
+func decodeSignature(signature, encoding string) ([]byte, error) {
+	switch encoding {
+	case "hex":
+		return decodeInput(strings.ToLower(signature), "hex")
+	case "base64", "base64url":
+		return decodeInput(signature, "base64")
+	default:
+		return nil, fmt.Errorf("unsupported signature encoding %q, expected hex, base64 or base64url", encoding)
+	}
+}
+
+// parseStripeSignature splits a Stripe-Signature header into its timestamp
+// and v1 signatures.
+func parseStripeSignature(header string) (string, []string) {
+	timestamp := ""
+	signatures := []string{}
+	for _, part := range strings.Split(header, ",") {
+		k, v, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch k {
+		case "t":
+			timestamp = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+	return timestamp, signatures
+}
+
+// timestampArg reads the timestamp argument, which models tend to send as
+// either a string or a number.
+func timestampArg(args map[string]interface{}) (string, bool) {
+	switch ts := args["timestamp"].(type) {
+	case string:
+		if ts == "" {
+			return "", false
+		}
+		return ts, true
+	case float64:
+		return strconv.FormatInt(int64(ts), 10), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// The github and slack vectors are the examples from their webhook
+// documentation, the stripe one was computed with openssl.
+const slackBody = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+
+var hmacPresetTests = []struct {
+	preset    string
+	key       string
+	text      string
+	timestamp string
+	header    string
+	signature string
+}{
+	{
+		preset:    "github",
+		key:       "It's a Secret to Everybody",
+		text:      "Hello, World!",
+		header:    "X-Hub-Signature-256",
+		signature: "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+	},
+	{
+		preset:    "slack",
+		key:       "8f742231b10e8888abcd99yyyzzz85a5",
+		text:      slackBody,
+		timestamp: "1531420618",
+		header:    "X-Slack-Signature",
+		signature: "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503",
+	},
+	{
+		preset:    "stripe",
+		key:       "whsec_test_secret",
+		text:      `{"id":"evt_test","object":"event"}`,
+		timestamp: "1614556800",
+		header:    "Stripe-Signature",
+		signature: "t=1614556800,v1=b0d2d9a36e336ac89c055bef9da2194fb43d132b8a924062e03bcc50d3b03f35",
+	},
+}
+
+func TestHMACSignPresets(t *testing.T) {
+	for _, tt := range hmacPresetTests {
+		t.Run(tt.preset, func(t *testing.T) {
+			args := map[string]interface{}{"preset": tt.preset, "key": tt.key, "text": tt.text}
+			if tt.timestamp != "" {
+				args["timestamp"] = tt.timestamp
+			}
+			result, err := runHMACSign(args)
+
+			var got HMACPresetSignature
+			resultJSON(t, result, err, &got)
+			if got.Header != tt.header || got.Signature != tt.signature || got.Timestamp != tt.timestamp {
+				t.Errorf("hmac_sign() = %+v, want %s: %s at %q", got, tt.header, tt.signature, tt.timestamp)
+			}
+		})
+	}
+}
+
+func TestHMACVerifyPresets(t *testing.T) {
+	for _, tt := range hmacPresetTests {
+		t.Run(tt.preset, func(t *testing.T) {
+			for _, text := range []string{tt.text, tt.text + " "} {
+				args := map[string]interface{}{"preset": tt.preset, "key": tt.key, "text": text, "signature": tt.signature}
+				if tt.timestamp != "" {
+					args["timestamp"] = tt.timestamp
+				}
+				result, err := runHMACVerify(args)
+
+				var got HMACVerification
+				resultJSON(t, result, err, &got)
+				if want := text == tt.text; got.Valid != want {
+					t.Errorf("hmac_verify() of %q = %+v, want valid %t", text, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("stripe outside the tolerance", func(t *testing.T) {
+		tt := hmacPresetTests[2]
+		result, err := runHMACVerify(map[string]interface{}{
+			"preset":    "stripe",
+			"key":       tt.key,
+			"text":      tt.text,
+			"signature": tt.signature,
+			"tolerance": float64(300),
+		})
+
+		var got HMACVerification
+		resultJSON(t, result, err, &got)
+		if got.Valid || got.Reason != "timestamp is outside the 300 second tolerance" {
+			t.Errorf("hmac_verify() = %+v, want rejected for its age", got)
+		}
+	})
+
+	t.Run("stripe with a rolled secret", func(t *testing.T) {
+		tt := hmacPresetTests[2]
+		result, err := runHMACVerify(map[string]interface{}{
+			"preset":    "stripe",
+			"key":       tt.key,
+			"text":      tt.text,
+			"signature": "t=1614556800,v1=" + strings.Repeat("0", 64) + ",v1=b0d2d9a36e336ac89c055bef9da2194fb43d132b8a924062e03bcc50d3b03f35",
+		})
+
+		var got HMACVerification
+		resultJSON(t, result, err, &got)
+		if !got.Valid {
+			t.Errorf("hmac_verify() = %+v, want one of the v1 signatures to match", got)
+		}
+	})
+
+	t.Run("github sha1", func(t *testing.T) {
+		result, err := runHMACVerify(map[string]interface{}{
+			"preset":    "github",
+			"key":       "It's a Secret to Everybody",
+			"text":      "Hello, World!",
+			"signature": "sha1=01dc10d0c83e72ed246219cdd91669667fe2ca59",
+		})
+
+		var got HMACVerification
+		resultJSON(t, result, err, &got)
+		if !got.Valid || got.Algorithm != "sha1" {
+			t.Errorf("hmac_verify() = %+v, want a valid sha1 signature", got)
+		}
+	})
+}
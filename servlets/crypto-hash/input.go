@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// inputProperties are the schema properties shared by every tool that hashes
+// caller-supplied data. Either text or path must be provided.
+var inputProperties = map[string]interface{}{
+	"text": map[string]interface{}{
+		"type":        "string",
+		"description": "the data to hash, interpreted according to `encoding`",
+	},
+	"encoding": map[string]interface{}{
+		"type":        "string",
+		"description": "how `text` is encoded: utf8 hashes the text as-is, hex and base64 decode it first so binary data can be hashed (default: utf8)",
+		"enum":        []string{"utf8", "hex", "base64"},
+		"default":     "utf8",
+	},
+	"path": map[string]interface{}{
+		"type":        "string",
+		"description": "path to a file to hash instead of `text`. The file paths must either be absolute or relative to the directory this servlet has access to. This servlet understands the following root directories: /, /home/, and /tmp",
+	},
+}
+
+// withProperties merges several schema property maps, later maps taking
+// precedence. Tools reading their data with openInput pass inputProperties
+// first.
+func withProperties(maps ...map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for _, m := range maps {
+		for k, v := range m {
+			properties[k] = v
+		}
+	}
+	return properties
+}
+
+// openInput returns a reader over the data described by the `path` or
+// `text` and `encoding` arguments. Files are streamed rather than read into
+// memory so large artifacts can be hashed.
+func openInput(args map[string]interface{}) (io.ReadCloser, error) {
+	if path, ok := args["path"].(string); ok && path != "" {
+		fullPath := wasiPath(path)
+
+		f, err := os.Open(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w. Trying to read from %s", err, fullPath)
+		}
+		return f, nil
+	}
+
+	text, ok := args["text"].(string)
+	if !ok {
+		return nil, errors.New("either text or path must be provided")
+	}
+
+	encoding, _ := args["encoding"].(string)
+	data, err := decodeInput(text, encoding)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// wasiPath resolves a path argument against the root of the directories
+// this servlet has been granted.
+func wasiPath(path string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.Join("/", path)
+	}
+	return path
+}
+
+// decodeInput converts text to bytes according to encoding. An empty
+// encoding means utf8.
+func decodeInput(text, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "", "utf8", "utf-8":
+		return []byte(text), nil
+	case "hex":
+		data, err := hex.DecodeString(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode hex input: %w", err)
+		}
+		return data, nil
+	case "base64":
+		data, err := decodeBase64(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 input: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q, expected utf8, hex or base64", encoding)
+	}
+}
+
+// decodeBase64 accepts standard and URL-safe base64, with or without padding.
+func decodeBase64(text string) ([]byte, error) {
+	text = strings.TrimSpace(text)
+	text = strings.TrimRight(text, "=")
+	if strings.ContainsAny(text, "-_") {
+		return base64.RawURLEncoding.DecodeString(text)
+	}
+	return base64.RawStdEncoding.DecodeString(text)
+}
+
+// hashInput streams the tool input into w.
+func hashInput(w io.Writer, args map[string]interface{}) error {
+	r, err := openInput(args)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	return nil
+}
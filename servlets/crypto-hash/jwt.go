@@ -0,0 +1,620 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	JWTDecodeTool = ToolDescription{
+		Name:        "jwt_decode",
+		Description: "Decode a JSON Web Token without verifying it. Returns the header and claims, and reports exp, nbf and iat against the current time.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"token"},
+			"properties": map[string]interface{}{
+				"token": map[string]interface{}{
+					"type":        "string",
+					"description": "the JWT to decode (a leading `Bearer ` is ignored)",
+				},
+			},
+		},
+	}
+	JWTSignTool = ToolDescription{
+		Name:        "jwt_sign",
+		Description: "Create a signed JSON Web Token. HS256/HS384/HS512 use a shared secret, RS256/RS384/RS512, ES256/ES384 and EdDSA use a PEM or JWK private key.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"claims", "algorithm", "key"},
+			"properties": map[string]interface{}{
+				"claims": map[string]interface{}{
+					"type":        "object",
+					"description": "the JWT claims (payload)",
+				},
+				"header": map[string]interface{}{
+					"type":        "object",
+					"description": "additional header fields such as kid. alg and typ are set automatically",
+				},
+				"algorithm": map[string]interface{}{
+					"type":        "string",
+					"description": "the signing algorithm",
+					"enum":        jwtAlgorithmNames,
+				},
+				"key": map[string]interface{}{
+					"type":        "string",
+					"description": "the HMAC secret, or a PEM or JWK private key for asymmetric algorithms",
+				},
+				"key_encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "how an HMAC secret is encoded (default: utf8)",
+					"enum":        []string{"utf8", "hex", "base64"},
+					"default":     "utf8",
+				},
+				"expires_in": map[string]interface{}{
+					"type":        "integer",
+					"description": "if set, sets iat to now and exp to now plus this many seconds",
+					"minimum":     1,
+				},
+			},
+		},
+	}
+	JWTVerifyTool = ToolDescription{
+		Name:        "jwt_verify",
+		Description: "Verify the signature and time claims of a JSON Web Token using an HMAC secret, a PEM or JWK public key, or a JWKS document. Tokens using alg `none` are always rejected.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"token"},
+			"properties": map[string]interface{}{
+				"token": map[string]interface{}{
+					"type":        "string",
+					"description": "the JWT to verify (a leading `Bearer ` is ignored)",
+				},
+				"key": map[string]interface{}{
+					"type":        "string",
+					"description": "the HMAC secret, or a PEM or JWK public key. HS* tokens are rejected when key is a public or private key. Either key or jwks must be provided",
+				},
+				"key_encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "how an HMAC secret is encoded (default: utf8)",
+					"enum":        []string{"utf8", "hex", "base64"},
+					"default":     "utf8",
+				},
+				"jwks": map[string]interface{}{
+					"type":        "string",
+					"description": "a JWKS document (`{\"keys\": [...]}`). The key is selected by the token's kid",
+				},
+				"algorithms": map[string]interface{}{
+					"type":        "array",
+					"description": "if set, only accept tokens signed with one of these algorithms",
+					"items": map[string]interface{}{
+						"type": "string",
+						"enum": jwtAlgorithmNames,
+					},
+				},
+				"leeway": map[string]interface{}{
+					"type":        "integer",
+					"description": "clock skew in seconds allowed when checking exp and nbf (default: 0)",
+					"minimum":     0,
+					"default":     0,
+				},
+			},
+		},
+	}
+)
+
+type jwtAlgorithm struct {
+	hash crypto.Hash
+	// kty is the JWK key type the algorithm requires.
+	kty string
+	// curveBytes is the size of r and s for ECDSA signatures.
+	curveBytes int
+}
+
+var jwtAlgorithms = map[string]jwtAlgorithm{
+	"HS256": {hash: crypto.SHA256, kty: "oct"},
+	"HS384": {hash: crypto.SHA384, kty: "oct"},
+	"HS512": {hash: crypto.SHA512, kty: "oct"},
+	"RS256": {hash: crypto.SHA256, kty: "RSA"},
+	"RS384": {hash: crypto.SHA384, kty: "RSA"},
+	"RS512": {hash: crypto.SHA512, kty: "RSA"},
+	"ES256": {hash: crypto.SHA256, kty: "EC", curveBytes: 32},
+	"ES384": {hash: crypto.SHA384, kty: "EC", curveBytes: 48},
+	"EdDSA": {kty: "OKP"},
+}
+
+var jwtAlgorithmNames = []string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}
+
+// JWTTime describes a NumericDate claim relative to the current time.
+type JWTTime struct {
+	Value    int64  `json:"value"`
+	Time     string `json:"time"`
+	Relative string `json:"relative"`
+}
+
+// DecodedJWT is returned by jwt_decode.
+type DecodedJWT struct {
+	Header      map[string]interface{} `json:"header"`
+	Claims      map[string]interface{} `json:"claims"`
+	Signature   string                 `json:"signature"`
+	Now         string                 `json:"now"`
+	IssuedAt    *JWTTime               `json:"iat,omitempty"`
+	NotBefore   *JWTTime               `json:"nbf,omitempty"`
+	ExpiresAt   *JWTTime               `json:"exp,omitempty"`
+	Expired     bool                   `json:"expired"`
+	NotYetValid bool                   `json:"not_yet_valid"`
+}
+
+// JWTVerification is returned by jwt_verify.
+type JWTVerification struct {
+	Valid     bool                   `json:"valid"`
+	Algorithm string                 `json:"algorithm"`
+	Kid       string                 `json:"kid,omitempty"`
+	Reason    string                 `json:"reason,omitempty"`
+	Header    map[string]interface{} `json:"header"`
+	Claims    map[string]interface{} `json:"claims"`
+}
+
+type jwtParts struct {
+	header       map[string]interface{}
+	claims       map[string]interface{}
+	signature    []byte
+	signingInput string
+}
+
+func splitJWT(token string) (jwtParts, error) {
+	token = strings.TrimSpace(token)
+	token = strings.TrimPrefix(token, "Bearer ")
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return jwtParts{}, fmt.Errorf("a JWT must have 3 dot separated segments, got %d", len(segments))
+	}
+
+	parts := jwtParts{signingInput: segments[0] + "." + segments[1]}
+	if err := decodeJWTSegment(segments[0], &parts.header); err != nil {
+		return jwtParts{}, fmt.Errorf("invalid header: %w", err)
+	}
+	if err := decodeJWTSegment(segments[1], &parts.claims); err != nil {
+		return jwtParts{}, fmt.Errorf("invalid claims: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segments[2], "="))
+	if err != nil {
+		return jwtParts{}, fmt.Errorf("invalid signature: %w", err)
+	}
+	parts.signature = signature
+	return parts, nil
+}
+
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+// numericClaim reads a NumericDate claim (seconds since the epoch).
+func numericClaim(claims map[string]interface{}, name string) (int64, bool) {
+	n, ok := claims[name].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	if i, err := n.Int64(); err == nil {
+		return i, true
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func describeJWTTime(value int64, now time.Time) *JWTTime {
+	t := time.Unix(value, 0).UTC()
+	d := t.Sub(now).Round(time.Second)
+	relative := "now"
+	if d > 0 {
+		relative = "in " + d.String()
+	} else if d < 0 {
+		relative = (-d).String() + " ago"
+	}
+	return &JWTTime{Value: value, Time: t.Format(time.RFC3339), Relative: relative}
+}
+
+func runJWTDecode(args map[string]interface{}) (CallToolResult, error) {
+	token, ok := args["token"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("token must be provided")
+	}
+
+	parts, err := splitJWT(token)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	now := time.Now().UTC()
+	out := DecodedJWT{
+		Header:    parts.header,
+		Claims:    parts.claims,
+		Signature: base64.RawURLEncoding.EncodeToString(parts.signature),
+		Now:       now.Format(time.RFC3339),
+	}
+	if iat, ok := numericClaim(parts.claims, "iat"); ok {
+		out.IssuedAt = describeJWTTime(iat, now)
+	}
+	if nbf, ok := numericClaim(parts.claims, "nbf"); ok {
+		out.NotBefore = describeJWTTime(nbf, now)
+		out.NotYetValid = now.Unix() < nbf
+	}
+	if exp, ok := numericClaim(parts.claims, "exp"); ok {
+		out.ExpiresAt = describeJWTTime(exp, now)
+		out.Expired = now.Unix() >= exp
+	}
+
+	return jsonResult(out)
+}
+
+// objectArg reads an argument that should be a JSON object. Models sometimes
+// send objects as JSON encoded strings, so both forms are accepted.
+func objectArg(args map[string]interface{}, name string) (map[string]interface{}, error) {
+	switch v := args[name].(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return v, nil
+	case string:
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(v), &obj); err != nil {
+			return nil, fmt.Errorf("%s must be a JSON object: %w", name, err)
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("%s must be a JSON object", name)
+	}
+}
+
+// jwtSecret returns the HMAC secret from the key argument, which may also be
+// an oct JWK.
+func jwtSecret(args map[string]interface{}, key string) ([]byte, error) {
+	if isJSON(key) {
+		var k JWK
+		if err := json.Unmarshal([]byte(key), &k); err != nil {
+			return nil, fmt.Errorf("invalid JWK: %w", err)
+		}
+		return k.SymmetricKey()
+	}
+	encoding, _ := args["key_encoding"].(string)
+	return decodeInput(key, encoding)
+}
+
+// isAsymmetricKey reports whether key is a public or private key rather than
+// an HMAC secret: PEM, an OpenSSH public key or a JWK that is not oct. Such a
+// key is often public, so accepting it as an HMAC secret would let anyone
+// holding it forge HS256 tokens.
+func isAsymmetricKey(key string) bool {
+	if isJSON(key) {
+		var k JWK
+		return json.Unmarshal([]byte(key), &k) == nil && k.Kty != "oct"
+	}
+	if strings.HasPrefix(strings.TrimSpace(key), "-----") {
+		return true
+	}
+	_, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+	return err == nil
+}
+
+func runJWTSign(args map[string]interface{}) (CallToolResult, error) {
+	claims, err := objectArg(args, "claims")
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if claims == nil {
+		return CallToolResult{}, errors.New("claims must be provided")
+	}
+	extraHeader, err := objectArg(args, "header")
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	name, ok := args["algorithm"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("algorithm must be provided")
+	}
+	alg, ok := jwtAlgorithms[name]
+	if !ok {
+		return CallToolResult{}, fmt.Errorf("unsupported algorithm %q, expected one of: %s", name, strings.Join(jwtAlgorithmNames, ", "))
+	}
+	key, ok := args["key"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("key must be provided")
+	}
+
+	if expiresIn, exists := args["expires_in"].(float64); exists {
+		if expiresIn < 1 {
+			return CallToolResult{}, errors.New("expires_in must be at least 1")
+		}
+		now := time.Now().Unix()
+		claims["iat"] = now
+		claims["exp"] = now + int64(expiresIn)
+	}
+
+	header := map[string]interface{}{}
+	for k, v := range extraHeader {
+		header[k] = v
+	}
+	header["alg"] = name
+	if _, ok := header["typ"]; !ok {
+		header["typ"] = "JWT"
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return CallToolResult{}, fmt.Errorf("failed to marshal header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return CallToolResult{}, fmt.Errorf("failed to marshal claims: %w", err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+
+	var signature []byte
+	if alg.kty == "oct" {
+		secret, err := jwtSecret(args, key)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		signature = jwtHMAC(alg, secret, signingInput)
+	} else {
+		signer, err := parsePrivateKey(key)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		signature, err = jwtSignAsymmetric(name, alg, signer, signingInput)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+	}
+
+	return textResult(signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)), nil
+}
+
+func jwtHMAC(alg jwtAlgorithm, secret []byte, signingInput string) []byte {
+	mac := hmac.New(alg.hash.New, secret)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+func jwtSignAsymmetric(name string, alg jwtAlgorithm, signer crypto.Signer, signingInput string) ([]byte, error) {
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		if alg.kty != "RSA" {
+			return nil, fmt.Errorf("%s requires an RSA key", name)
+		}
+		h := alg.hash.New()
+		h.Write([]byte(signingInput))
+		return rsa.SignPKCS1v15(rand.Reader, key, alg.hash, h.Sum(nil))
+	case *ecdsa.PrivateKey:
+		if alg.kty != "EC" || key.Curve.Params().BitSize != alg.curveBytes*8 {
+			return nil, fmt.Errorf("%s requires an EC key on the matching curve", name)
+		}
+		h := alg.hash.New()
+		h.Write([]byte(signingInput))
+		r, s, err := ecdsa.Sign(rand.Reader, key, h.Sum(nil))
+		if err != nil {
+			return nil, err
+		}
+		// JWS uses the fixed-size r || s encoding rather than ASN.1.
+		signature := make([]byte, 2*alg.curveBytes)
+		r.FillBytes(signature[:alg.curveBytes])
+		s.FillBytes(signature[alg.curveBytes:])
+		return signature, nil
+	case ed25519.PrivateKey:
+		if alg.kty != "OKP" {
+			return nil, fmt.Errorf("%s requires an Ed25519 key", name)
+		}
+		return ed25519.Sign(key, []byte(signingInput)), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", signer)
+	}
+}
+
+func jwtVerifyAsymmetric(name string, alg jwtAlgorithm, pub crypto.PublicKey, signingInput string, signature []byte) error {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		if alg.kty != "RSA" {
+			return fmt.Errorf("%s cannot be verified with an RSA key", name)
+		}
+		h := alg.hash.New()
+		h.Write([]byte(signingInput))
+		return rsa.VerifyPKCS1v15(key, alg.hash, h.Sum(nil), signature)
+	case *ecdsa.PublicKey:
+		if alg.kty != "EC" || key.Curve.Params().BitSize != alg.curveBytes*8 {
+			return fmt.Errorf("%s cannot be verified with this EC key", name)
+		}
+		if len(signature) != 2*alg.curveBytes {
+			return errors.New("invalid ECDSA signature length")
+		}
+		h := alg.hash.New()
+		h.Write([]byte(signingInput))
+		r := new(big.Int).SetBytes(signature[:alg.curveBytes])
+		s := new(big.Int).SetBytes(signature[alg.curveBytes:])
+		if !ecdsa.Verify(key, h.Sum(nil), r, s) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if alg.kty != "OKP" {
+			return fmt.Errorf("%s cannot be verified with an Ed25519 key", name)
+		}
+		if !ed25519.Verify(key, []byte(signingInput), signature) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// jwksCandidates returns the keys of a JWKS that may have signed a token
+// with the given kid and algorithm.
+func jwksCandidates(text, kid string, alg jwtAlgorithm) ([]JWK, error) {
+	var set JWKS
+	if err := json.Unmarshal([]byte(text), &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	candidates := []JWK{}
+	for _, k := range set.Keys {
+		if kid != "" && k.Kid != kid {
+			continue
+		}
+		if k.Kty != alg.kty || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		candidates = append(candidates, k)
+	}
+	if len(candidates) == 0 {
+		if kid != "" {
+			return nil, fmt.Errorf("no %s key with kid %q in JWKS", alg.kty, kid)
+		}
+		return nil, fmt.Errorf("no %s key in JWKS", alg.kty)
+	}
+	return candidates, nil
+}
+
+func runJWTVerify(args map[string]interface{}) (CallToolResult, error) {
+	token, ok := args["token"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("token must be provided")
+	}
+	key, hasKey := args["key"].(string)
+	jwks, hasJWKS := args["jwks"].(string)
+	if !hasKey && !hasJWKS {
+		if obj, ok := args["jwks"].(map[string]interface{}); ok {
+			b, _ := json.Marshal(obj)
+			jwks, hasJWKS = string(b), true
+		} else {
+			return CallToolResult{}, errors.New("either key or jwks must be provided")
+		}
+	}
+
+	leeway := int64(0)
+	if leewayVal, exists := args["leeway"].(float64); exists {
+		leeway = int64(leewayVal)
+		if leeway < 0 {
+			return CallToolResult{}, errors.New("leeway must not be negative")
+		}
+	}
+
+	parts, err := splitJWT(token)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	name, _ := parts.header["alg"].(string)
+	kid, _ := parts.header["kid"].(string)
+	result := JWTVerification{
+		Algorithm: name,
+		Kid:       kid,
+		Header:    parts.header,
+		Claims:    parts.claims,
+	}
+
+	alg, ok := jwtAlgorithms[name]
+	if !ok {
+		result.Reason = fmt.Sprintf("unsupported or insecure algorithm %q", name)
+		return jsonResult(result)
+	}
+	if allowed, ok := args["algorithms"].([]interface{}); ok && len(allowed) > 0 {
+		permitted := false
+		for _, a := range allowed {
+			if a == name {
+				permitted = true
+			}
+		}
+		if !permitted {
+			result.Reason = fmt.Sprintf("algorithm %s is not in the allowed list", name)
+			return jsonResult(result)
+		}
+	}
+
+	var verifyErr error
+	switch {
+	case alg.kty == "oct" && hasKey && isAsymmetricKey(key):
+		verifyErr = fmt.Errorf("algorithm %s requires an HMAC secret, but key is a public or private key", name)
+	case alg.kty == "oct" && hasKey:
+		secret, err := jwtSecret(args, key)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		if !hmac.Equal(jwtHMAC(alg, secret, parts.signingInput), parts.signature) {
+			verifyErr = errors.New("signature mismatch")
+		}
+	case hasKey:
+		pub, err := parsePublicKey(key)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		verifyErr = jwtVerifyAsymmetric(name, alg, pub, parts.signingInput, parts.signature)
+	default:
+		candidates, err := jwksCandidates(jwks, kid, alg)
+		if err != nil {
+			result.Reason = err.Error()
+			return jsonResult(result)
+		}
+		verifyErr = errors.New("signature mismatch")
+		for _, k := range candidates {
+			if alg.kty == "oct" {
+				secret, err := k.SymmetricKey()
+				if err == nil && hmac.Equal(jwtHMAC(alg, secret, parts.signingInput), parts.signature) {
+					verifyErr = nil
+					break
+				}
+				continue
+			}
+			pub, err := k.PublicKey()
+			if err != nil {
+				continue
+			}
+			if jwtVerifyAsymmetric(name, alg, pub, parts.signingInput, parts.signature) == nil {
+				verifyErr = nil
+				if result.Kid == "" {
+					result.Kid = k.Kid
+				}
+				break
+			}
+		}
+	}
+	if verifyErr != nil {
+		result.Reason = verifyErr.Error()
+		return jsonResult(result)
+	}
+
+	now := time.Now().Unix()
+	if exp, ok := numericClaim(parts.claims, "exp"); ok && now >= exp+leeway {
+		result.Reason = fmt.Sprintf("token expired at %s", time.Unix(exp, 0).UTC().Format(time.RFC3339))
+		return jsonResult(result)
+	}
+	if nbf, ok := numericClaim(parts.claims, "nbf"); ok && now < nbf-leeway {
+		result.Reason = fmt.Sprintf("token is not valid before %s", time.Unix(nbf, 0).UTC().Format(time.RFC3339))
+		return jsonResult(result)
+	}
+
+	result.Valid = true
+	return jsonResult(result)
+}
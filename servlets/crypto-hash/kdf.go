@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+var KDFTool = ToolDescription{
+	Name:        "kdf",
+	Description: "Derive raw key bytes with HKDF (RFC 5869), PBKDF2, scrypt or argon2id, for reproducing key derivations rather than storing passwords. The input keying material or password is taken from text or path. Either text or path must be provided.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"algorithm"},
+		"properties": withProperties(inputProperties, map[string]interface{}{
+			"algorithm": map[string]interface{}{
+				"type":        "string",
+				"description": "hkdf runs extract then expand. hkdf-extract returns the pseudorandom key (PRK) and ignores length and info. hkdf-expand treats the input as a PRK",
+				"enum":        []string{"hkdf", "hkdf-extract", "hkdf-expand", "pbkdf2", "scrypt", "argon2id"},
+			},
+			"hash": map[string]interface{}{
+				"type":        "string",
+				"description": "the hash function for HKDF and PBKDF2 (default: sha256)",
+				"enum":        []string{"sha1", "sha256", "sha384", "sha512"},
+				"default":     "sha256",
+			},
+			"salt": map[string]interface{}{
+				"type":        "string",
+				"description": "the salt, interpreted according to `salt_encoding`. Optional for HKDF, required otherwise",
+			},
+			"salt_encoding": map[string]interface{}{
+				"type":        "string",
+				"description": "how `salt` is encoded (default: utf8)",
+				"enum":        []string{"utf8", "hex", "base64"},
+				"default":     "utf8",
+			},
+			"info": map[string]interface{}{
+				"type":        "string",
+				"description": "HKDF context and application specific information, interpreted according to `info_encoding`",
+			},
+			"info_encoding": map[string]interface{}{
+				"type":        "string",
+				"description": "how `info` is encoded (default: utf8)",
+				"enum":        []string{"utf8", "hex", "base64"},
+				"default":     "utf8",
+			},
+			"length": map[string]interface{}{
+				"type":        "integer",
+				"description": "number of bytes to derive (default: 32)",
+				"minimum":     1,
+				"maximum":     1024,
+				"default":     32,
+			},
+			"iterations": map[string]interface{}{
+				"type":        "integer",
+				"description": "PBKDF2 iterations (default: 600000) or argon2id passes (default: 2)",
+				"minimum":     1,
+			},
+			"memory": map[string]interface{}{
+				"type":        "integer",
+				"description": "argon2id memory in KiB, at least 8 per unit of parallelism (default: 19456)",
+				"minimum":     8,
+				"maximum":     1048576,
+			},
+			"parallelism": map[string]interface{}{
+				"type":        "integer",
+				"description": "argon2id lanes (default: 1)",
+				"minimum":     1,
+				"maximum":     255,
+			},
+			"ln": map[string]interface{}{
+				"type":        "integer",
+				"description": "scrypt CPU/memory cost as a power of two, N = 2^ln (default: 15)",
+				"minimum":     1,
+				"maximum":     20,
+			},
+			"r": map[string]interface{}{
+				"type":        "integer",
+				"description": "scrypt block size (default: 8). scrypt uses 128 * r * 2^ln bytes of memory, at most 1 GiB",
+				"minimum":     1,
+				"maximum":     32,
+			},
+			"p": map[string]interface{}{
+				"type":        "integer",
+				"description": "scrypt parallelization (default: 1)",
+				"minimum":     1,
+				"maximum":     16,
+			},
+			"output_encoding": map[string]interface{}{
+				"type":        "string",
+				"description": "encoding of the derived key (default: hex)",
+				"enum":        []string{"hex", "base64", "base64url", "base32"},
+				"default":     "hex",
+			},
+		}),
+	},
+}
+
+// DerivedKey is returned by kdf.
+type DerivedKey struct {
+	Algorithm  string                 `json:"algorithm"`
+	Key        string                 `json:"key"`
+	Length     int                    `json:"length"`
+	Parameters map[string]interface{} `json:"parameters"`
+}
+
+// encodedArg decodes an optional string argument according to its
+// companion <name>_encoding argument.
+func encodedArg(args map[string]interface{}, name string) ([]byte, bool, error) {
+	value, exists := args[name].(string)
+	if !exists {
+		return nil, false, nil
+	}
+	encoding, _ := args[name+"_encoding"].(string)
+	b, err := decodeInput(value, encoding)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return b, true, nil
+}
+
+func runKDF(args map[string]interface{}) (CallToolResult, error) {
+	algorithm, ok := args["algorithm"].(string)
+	if !ok || algorithm == "" {
+		return CallToolResult{}, errors.New("algorithm must be provided")
+	}
+	length, err := intArg(args, "length", 32, 1, 1024)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	hashName := "sha256"
+	if hashVal, exists := args["hash"].(string); exists && hashVal != "" {
+		hashName = hashVal
+	}
+	switch hashName {
+	case "sha1", "sha256", "sha384", "sha512":
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported hash %q, expected sha1, sha256, sha384 or sha512", hashName)
+	}
+	newHash := func() hash.Hash {
+		h, _ := newDigest(hashName, 0)
+		return h
+	}
+
+	secret, err := readInput(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	salt, hasSalt, err := encodedArg(args, "salt")
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	info, _, err := encodedArg(args, "info")
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	params := map[string]interface{}{}
+	var key []byte
+	switch algorithm {
+	case "hkdf", "hkdf-expand":
+		if max := 255 * newHash().Size(); length > max {
+			return CallToolResult{}, fmt.Errorf("HKDF with %s can derive at most %d bytes", hashName, max)
+		}
+		var r io.Reader
+		if algorithm == "hkdf" {
+			r = hkdf.New(newHash, secret, salt, info)
+		} else {
+			r = hkdf.Expand(newHash, secret, info)
+		}
+		key = make([]byte, length)
+		if _, err := io.ReadFull(r, key); err != nil {
+			return CallToolResult{}, err
+		}
+		params["hash"] = hashName
+	case "hkdf-extract":
+		key = hkdf.Extract(newHash, secret, salt)
+		params["hash"] = hashName
+	case "pbkdf2", "scrypt", "argon2id":
+		if !hasSalt {
+			return CallToolResult{}, fmt.Errorf("salt must be provided for %s", algorithm)
+		}
+		switch algorithm {
+		case "pbkdf2":
+			iterations, err := intArg(args, "iterations", 600000, 1, 10000000)
+			if err != nil {
+				return CallToolResult{}, err
+			}
+			key = pbkdf2.Key(secret, salt, iterations, length, newHash)
+			params["hash"] = hashName
+			params["iterations"] = iterations
+		case "scrypt":
+			ln, err := intArg(args, "ln", 15, 1, 20)
+			if err != nil {
+				return CallToolResult{}, err
+			}
+			r, err := intArg(args, "r", 8, 1, 32)
+			if err != nil {
+				return CallToolResult{}, err
+			}
+			p, err := intArg(args, "p", 1, 1, 16)
+			if err != nil {
+				return CallToolResult{}, err
+			}
+			if err := checkScryptMemory(ln, r); err != nil {
+				return CallToolResult{}, err
+			}
+			if key, err = scrypt.Key(secret, salt, 1<<ln, r, p, length); err != nil {
+				return CallToolResult{}, fmt.Errorf("scrypt error: %v", err)
+			}
+			params["N"] = 1 << ln
+			params["r"] = r
+			params["p"] = p
+		case "argon2id":
+			iterations, err := intArg(args, "iterations", 2, 1, 100)
+			if err != nil {
+				return CallToolResult{}, err
+			}
+			memory, err := intArg(args, "memory", 19456, 8, 1048576)
+			if err != nil {
+				return CallToolResult{}, err
+			}
+			parallelism, err := intArg(args, "parallelism", 1, 1, 255)
+			if err != nil {
+				return CallToolResult{}, err
+			}
+			if memory < 8*parallelism {
+				return CallToolResult{}, errors.New("memory must be at least 8 KiB per unit of parallelism")
+			}
+			key = argon2.IDKey(secret, salt, uint32(iterations), uint32(memory), uint8(parallelism), uint32(length))
+			params["iterations"] = iterations
+			params["memory"] = memory
+			params["parallelism"] = parallelism
+		}
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported algorithm %q, expected hkdf, hkdf-extract, hkdf-expand, pbkdf2, scrypt or argon2id", algorithm)
+	}
+
+	encoding := "hex"
+	if encodingVal, exists := args["output_encoding"].(string); exists && encodingVal != "" {
+		encoding = encodingVal
+	}
+	encoded, err := encodeDigest(key, encoding)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	return jsonResult(DerivedKey{Algorithm: algorithm, Key: encoded, Length: len(key), Parameters: params})
+}
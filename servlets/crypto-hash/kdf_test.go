@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKDFScryptRFC7914(t *testing.T) {
+	result, err := runKDF(map[string]interface{}{
+		"algorithm": "scrypt",
+		"text":      "password",
+		"salt":      "NaCl",
+		"ln":        float64(10),
+		"r":         float64(8),
+		"p":         float64(16),
+		"length":    float64(64),
+	})
+
+	var got DerivedKey
+	resultJSON(t, result, err, &got)
+	want := "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"
+	if got.Key != want {
+		t.Errorf("kdf() = %s, want %s", got.Key, want)
+	}
+}
+
+func TestKDFRejectsCosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:    "scrypt over 1 GiB",
+			args:    map[string]interface{}{"algorithm": "scrypt", "ln": float64(20), "r": float64(16)},
+			wantErr: "more than 1 GiB",
+		},
+		{
+			name:    "argon2id memory below 8 KiB per lane",
+			args:    map[string]interface{}{"algorithm": "argon2id", "memory": float64(16), "parallelism": float64(4)},
+			wantErr: "at least 8 KiB per unit of parallelism",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args["text"] = "password"
+			tt.args["salt"] = "saltsalt"
+			_, err := runKDF(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("kdf() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
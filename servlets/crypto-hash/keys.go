@@ -0,0 +1,365 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// JWK is a JSON Web Key (RFC 7517). Only the members needed for RSA, EC,
+// OKP (Ed25519) and symmetric keys are modelled.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	D   string `json:"d,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+	DP  string `json:"dp,omitempty"`
+	DQ  string `json:"dq,omitempty"`
+	QI  string `json:"qi,omitempty"`
+	K   string `json:"k,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// isJSON reports whether text looks like a JSON object rather than PEM.
+func isJSON(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), "{")
+}
+
+// parsePrivateKey parses a PEM encoded (PKCS#8, PKCS#1, SEC 1 or OpenSSH)
+// or JWK private key.
+func parsePrivateKey(text string) (crypto.Signer, error) {
+	if isJSON(text) {
+		var k JWK
+		if err := json.Unmarshal([]byte(text), &k); err != nil {
+			return nil, fmt.Errorf("invalid JWK: %w", err)
+		}
+		return k.PrivateKey()
+	}
+
+	block, _ := pem.Decode([]byte(strings.TrimSpace(text)))
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded or a JWK")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	case "OPENSSH PRIVATE KEY":
+		key, err := ssh.ParseRawPrivateKey(pem.EncodeToMemory(block))
+		if err != nil {
+			return nil, err
+		}
+		// ssh returns Ed25519 keys by pointer
+		if k, ok := key.(*ed25519.PrivateKey); ok {
+			return *k, nil
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q, expected a private key", block.Type)
+	}
+}
+
+// parsePublicKey parses a PEM encoded (PKIX, PKCS#1 or certificate), JWK or
+// OpenSSH authorized_keys public key. Private keys are accepted too, and
+// their public half returned.
+func parsePublicKey(text string) (crypto.PublicKey, error) {
+	if isJSON(text) {
+		var k JWK
+		if err := json.Unmarshal([]byte(text), &k); err != nil {
+			return nil, fmt.Errorf("invalid JWK: %w", err)
+		}
+		return k.PublicKey()
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(text), "-----") {
+		sshKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(text))
+		if err != nil {
+			return nil, errors.New("key must be PEM encoded, a JWK or an OpenSSH public key")
+		}
+		cryptoKey, ok := sshKey.(ssh.CryptoPublicKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported OpenSSH key type %s", sshKey.Type())
+		}
+		return cryptoKey.CryptoPublicKey(), nil
+	}
+
+	block, _ := pem.Decode([]byte(strings.TrimSpace(text)))
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded or a JWK")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		signer, err := parsePrivateKey(text)
+		if err != nil {
+			return nil, err
+		}
+		return signer.Public(), nil
+	}
+}
+
+func jwkBytes(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("JWK is missing %q", name)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWK %q: %w", name, err)
+	}
+	return b, nil
+}
+
+func jwkInt(name, value string) (*big.Int, error) {
+	b, err := jwkBytes(name, value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func jwkCurve(crv string) (elliptic.Curve, error) {
+	switch crv {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("unsupported EC curve %q", crv)
+	}
+}
+
+// PublicKey returns the public key described by k.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := jwkInt("n", k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := jwkInt("e", k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, err := jwkCurve(k.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, err := jwkInt("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := jwkInt("y", k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("JWK point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", k.Crv)
+		}
+		x, err := jwkBytes("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key length")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		return nil, errors.New("symmetric (oct) JWKs have no public key")
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type %q", k.Kty)
+	}
+}
+
+// PrivateKey returns the private key described by k.
+func (k JWK) PrivateKey() (crypto.Signer, error) {
+	if k.D == "" {
+		return nil, errors.New("JWK does not contain a private key")
+	}
+
+	pub, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		d, err := jwkInt("d", k.D)
+		if err != nil {
+			return nil, err
+		}
+		p, err := jwkInt("p", k.P)
+		if err != nil {
+			return nil, err
+		}
+		q, err := jwkInt("q", k.Q)
+		if err != nil {
+			return nil, err
+		}
+		key := &rsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+		if err := key.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid RSA JWK: %w", err)
+		}
+		key.Precompute()
+		return key, nil
+	case *ecdsa.PublicKey:
+		d, err := jwkInt("d", k.D)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PrivateKey{PublicKey: *pub, D: d}, nil
+	case ed25519.PublicKey:
+		seed, err := jwkBytes("d", k.D)
+		if err != nil {
+			return nil, err
+		}
+		if len(seed) != ed25519.SeedSize {
+			return nil, errors.New("invalid Ed25519 private key length")
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type %q", k.Kty)
+	}
+}
+
+// SymmetricKey returns the secret of an oct JWK.
+func (k JWK) SymmetricKey() ([]byte, error) {
+	if k.Kty != "oct" {
+		return nil, fmt.Errorf("expected an oct JWK, got %q", k.Kty)
+	}
+	return jwkBytes("k", k.K)
+}
+
+func b64url(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// publicJWK returns the JWK representation of a public key.
+func publicJWK(pub crypto.PublicKey) (JWK, error) {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   b64url(key.N.Bytes()),
+			E:   b64url(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kty: "EC",
+			Crv: key.Curve.Params().Name,
+			X:   b64url(key.X.FillBytes(make([]byte, size))),
+			Y:   b64url(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Crv: "Ed25519", X: b64url(key)}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// privateJWK returns the JWK representation of a private key.
+func privateJWK(signer crypto.Signer) (JWK, error) {
+	k, err := publicJWK(signer.Public())
+	if err != nil {
+		return JWK{}, err
+	}
+
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		key.Precompute()
+		k.D = b64url(key.D.Bytes())
+		k.P = b64url(key.Primes[0].Bytes())
+		k.Q = b64url(key.Primes[1].Bytes())
+		k.DP = b64url(key.Precomputed.Dp.Bytes())
+		k.DQ = b64url(key.Precomputed.Dq.Bytes())
+		k.QI = b64url(key.Precomputed.Qinv.Bytes())
+	case *ecdsa.PrivateKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		k.D = b64url(key.D.FillBytes(make([]byte, size)))
+	case ed25519.PrivateKey:
+		k.D = b64url(key.Seed())
+	default:
+		return JWK{}, fmt.Errorf("unsupported private key type %T", signer)
+	}
+	return k, nil
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of the public part of
+// k, commonly used as its kid.
+func (k JWK) Thumbprint() (string, error) {
+	var members string
+	switch k.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, k.Crv, k.X, k.Y)
+	case "OKP":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, k.Crv, k.X)
+	case "oct":
+		members = fmt.Sprintf(`{"k":%q,"kty":"oct"}`, k.K)
+	default:
+		return "", fmt.Errorf("unsupported JWK key type %q", k.Kty)
+	}
+	sum := sha256.Sum256([]byte(members))
+	return b64url(sum[:]), nil
+}
@@ -7,6 +7,7 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -42,19 +43,151 @@ func Call(input CallToolRequest) (CallToolResult, error) {
 	case "bcrypt":
 		return runBcrypt(argsMap)
 
+	case PasswordVerifyTool.Name:
+		return runPasswordVerify(argsMap)
+
+	case Argon2idTool.Name:
+		return runArgon2id(argsMap)
+
+	case ScryptTool.Name:
+		return runScrypt(argsMap)
+
+	case PBKDF2Tool.Name:
+		return runPBKDF2(argsMap)
+
+	case DigestTool.Name:
+		return runDigest(argsMap)
+
+	case JWTDecodeTool.Name:
+		return runJWTDecode(argsMap)
+
+	case JWTSignTool.Name:
+		return runJWTSign(argsMap)
+
+	case JWTVerifyTool.Name:
+		return runJWTVerify(argsMap)
+
+	case KeyGenerateTool.Name:
+		return runKeyGenerate(argsMap)
+
+	case SignTool.Name:
+		return runSign(argsMap)
+
+	case SignatureVerifyTool.Name:
+		return runSignatureVerify(argsMap)
+
+	case HMACSignTool.Name:
+		return runHMACSign(argsMap)
+
+	case HMACVerifyTool.Name:
+		return runHMACVerify(argsMap)
+
+	case EncryptTool.Name:
+		return runEncrypt(argsMap)
+
+	case DecryptTool.Name:
+		return runDecrypt(argsMap)
+
+	case TOTPGenerateTool.Name:
+		return runTOTPGenerate(argsMap)
+
+	case TOTPVerifyTool.Name:
+		return runTOTPVerify(argsMap)
+
+	case OTPAuthParseTool.Name:
+		return runOTPAuthParse(argsMap)
+
+	case X509InspectTool.Name:
+		return runX509Inspect(argsMap)
+
+	case RandomUUIDTool.Name:
+		return runRandomUUID(argsMap)
+
+	case RandomULIDTool.Name:
+		return runRandomULID(argsMap)
+
+	case RandomNanoIDTool.Name:
+		return runRandomNanoID(argsMap)
+
+	case RandomBytesTool.Name:
+		return runRandomBytes(argsMap)
+
+	case RandomPasswordTool.Name:
+		return runRandomPassword(argsMap)
+
+	case RandomPassphraseTool.Name:
+		return runRandomPassphrase(argsMap)
+
+	case ChecksumTool.Name:
+		return runChecksum(argsMap)
+
+	case IPFSCIDTool.Name:
+		return runIPFSCID(argsMap)
+
+	case GitObjectIDTool.Name:
+		return runGitObjectID(argsMap)
+
+	case MerkleRootTool.Name:
+		return runMerkleRoot(argsMap)
+
+	case MerkleProofTool.Name:
+		return runMerkleProof(argsMap)
+
+	case MerkleVerifyTool.Name:
+		return runMerkleVerify(argsMap)
+
+	case SecretSplitTool.Name:
+		return runSecretSplit(argsMap)
+
+	case SecretCombineTool.Name:
+		return runSecretCombine(argsMap)
+
+	case SSHKeyInspectTool.Name:
+		return runSSHKeyInspect(argsMap)
+
+	case SSHKnownHostsTool.Name:
+		return runSSHKnownHosts(argsMap)
+
+	case HashBatchTool.Name:
+		return runHashBatch(argsMap)
+
+	case VerifyManifestTool.Name:
+		return runVerifyManifest(argsMap)
+
+	case KDFTool.Name:
+		return runKDF(argsMap)
+
 	default:
 		return CallToolResult{}, errors.New("Unknown tool")
 	}
 }
 
-func runSHA1(argsMap map[string]interface{}) (CallToolResult, error) {
-	text, ok := argsMap["text"].(string)
-	if !ok {
-		return CallToolResult{}, errors.New("text must be provided")
+// textResult wraps a plain text tool response.
+func textResult(text string) CallToolResult {
+	return CallToolResult{
+		Content: []Content{
+			{
+				Type: ContentTypeText,
+				Text: &text,
+			},
+		},
 	}
+}
+
+// jsonResult marshals v as the text content of a tool response.
+func jsonResult(v interface{}) (CallToolResult, error) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return CallToolResult{}, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return textResult(string(out)), nil
+}
 
+func runSHA1(argsMap map[string]interface{}) (CallToolResult, error) {
 	h1 := sha1.New()
-	h1.Write([]byte(text))
+	if err := hashInput(h1, argsMap); err != nil {
+		return CallToolResult{}, err
+	}
 	hash := hex.EncodeToString(h1.Sum(nil))
 
 	return CallToolResult{
@@ -68,13 +201,10 @@ func runSHA1(argsMap map[string]interface{}) (CallToolResult, error) {
 }
 
 func runSHA256(argsMap map[string]interface{}) (CallToolResult, error) {
-	text, ok := argsMap["text"].(string)
-	if !ok {
-		return CallToolResult{}, errors.New("text must be provided")
-	}
-
 	h256 := sha256.New()
-	h256.Write([]byte(text))
+	if err := hashInput(h256, argsMap); err != nil {
+		return CallToolResult{}, err
+	}
 	hash := hex.EncodeToString(h256.Sum(nil))
 
 	return CallToolResult{
@@ -88,12 +218,12 @@ func runSHA256(argsMap map[string]interface{}) (CallToolResult, error) {
 }
 
 func runMD5(args map[string]interface{}) (CallToolResult, error) {
-	text, ok := args["text"].(string)
-	if !ok {
-		return CallToolResult{}, errors.New("text must be provided")
+	h := md5.New()
+	if err := hashInput(h, args); err != nil {
+		return CallToolResult{}, err
 	}
 
-	hash := fmt.Sprintf("%x", md5.Sum([]byte(text)))
+	hash := fmt.Sprintf("%x", h.Sum(nil))
 	return CallToolResult{
 		Content: []Content{
 			{
@@ -144,44 +274,26 @@ func Describe() (ListToolsResult, error) {
 		Tools: []ToolDescription{
 			{
 				Name:        "md5",
-				Description: "Hash a string using MD5 (Note: MD5 is not cryptographically secure, use for checksums only)",
+				Description: "Hash a string or file using MD5 (Note: MD5 is not cryptographically secure, use for checksums only). Either text or path must be provided.",
 				InputSchema: map[string]interface{}{
-					"type":     "object",
-					"required": []string{"text"},
-					"properties": map[string]interface{}{
-						"text": map[string]interface{}{
-							"type":        "string",
-							"description": "the text to hash",
-						},
-					},
+					"type":       "object",
+					"properties": inputProperties,
 				},
 			},
 			{
 				Name:        "sha1",
-				Description: "Hash a string using SHA-1 (Note: SHA-1 is not recommended for new applications)",
+				Description: "Hash a string or file using SHA-1 (Note: SHA-1 is not recommended for new applications). Either text or path must be provided.",
 				InputSchema: map[string]interface{}{
-					"type":     "object",
-					"required": []string{"text"},
-					"properties": map[string]interface{}{
-						"text": map[string]interface{}{
-							"type":        "string",
-							"description": "the text to hash",
-						},
-					},
+					"type":       "object",
+					"properties": inputProperties,
 				},
 			},
 			{
 				Name:        "sha256",
-				Description: "Hash a string using SHA-256 (also called SHA2, cryptographically secure)",
+				Description: "Hash a string or file using SHA-256 (also called SHA2, cryptographically secure). Either text or path must be provided.",
 				InputSchema: map[string]interface{}{
-					"type":     "object",
-					"required": []string{"text"},
-					"properties": map[string]interface{}{
-						"text": map[string]interface{}{
-							"type":        "string",
-							"description": "the text to hash",
-						},
-					},
+					"type":       "object",
+					"properties": inputProperties,
 				},
 			},
 			{
@@ -205,6 +317,44 @@ func Describe() (ListToolsResult, error) {
 					},
 				},
 			},
+			PasswordVerifyTool,
+			Argon2idTool,
+			ScryptTool,
+			PBKDF2Tool,
+			DigestTool,
+			HMACSignTool,
+			HMACVerifyTool,
+			JWTDecodeTool,
+			JWTSignTool,
+			JWTVerifyTool,
+			KeyGenerateTool,
+			SignTool,
+			SignatureVerifyTool,
+			EncryptTool,
+			DecryptTool,
+			TOTPGenerateTool,
+			TOTPVerifyTool,
+			OTPAuthParseTool,
+			X509InspectTool,
+			RandomUUIDTool,
+			RandomULIDTool,
+			RandomNanoIDTool,
+			RandomBytesTool,
+			RandomPasswordTool,
+			RandomPassphraseTool,
+			ChecksumTool,
+			IPFSCIDTool,
+			GitObjectIDTool,
+			MerkleRootTool,
+			MerkleProofTool,
+			MerkleVerifyTool,
+			SecretSplitTool,
+			SecretCombineTool,
+			SSHKeyInspectTool,
+			SSHKnownHostsTool,
+			HashBatchTool,
+			VerifyManifestTool,
+			KDFTool,
 		},
 	}, nil
 }
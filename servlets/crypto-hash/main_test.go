@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// resultText returns the text of a tool result, failing the test on error.
+func resultText(t *testing.T, result CallToolResult, err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(result.Content) != 1 || result.Content[0].Text == nil {
+		t.Fatalf("result = %+v, want a single text block", result.Content)
+	}
+	return *result.Content[0].Text
+}
+
+// resultJSON decodes the JSON text of a tool result into v.
+func resultJSON(t *testing.T, result CallToolResult, err error, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(resultText(t, result, err)), v); err != nil {
+		t.Fatalf("result is not JSON: %s", err)
+	}
+}
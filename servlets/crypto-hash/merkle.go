@@ -0,0 +1,483 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var merkleProperties = map[string]interface{}{
+	"hash": map[string]interface{}{
+		"type":        "string",
+		"description": "the hash function (default: sha256)",
+		"enum":        []string{"sha256", "keccak256"},
+		"default":     "sha256",
+	},
+	"convention": map[string]interface{}{
+		"type":        "string",
+		"description": "rfc6962 is the Certificate Transparency tree, with 0x00/0x01 domain separation and a left-balanced split. sorted-pair hashes each pair in ascending byte order and promotes an odd node unchanged, as OpenZeppelin MerkleProof and merkletreejs with sortPairs expect (default: rfc6962)",
+		"enum":        []string{"rfc6962", "sorted-pair"},
+		"default":     "rfc6962",
+	},
+	"leaf_encoding": map[string]interface{}{
+		"type":        "string",
+		"description": "how leaf strings are encoded (default: utf8)",
+		"enum":        []string{"utf8", "hex", "base64"},
+		"default":     "utf8",
+	},
+	"hash_leaves": map[string]interface{}{
+		"type":        "boolean",
+		"description": "hash leaves before building the tree. Set to false when leaves are already leaf hashes (default: true)",
+		"default":     true,
+	},
+}
+
+var merkleLeavesProperties = withProperties(merkleProperties, map[string]interface{}{
+	"leaves": map[string]interface{}{
+		"type":        "array",
+		"description": "the leaves, in tree order",
+		"items":       map[string]interface{}{"type": "string"},
+	},
+	"sort_leaves": map[string]interface{}{
+		"type":        "boolean",
+		"description": "sort leaf hashes before building the tree, as merkletreejs sortLeaves does (default: false)",
+		"default":     false,
+	},
+})
+
+var (
+	MerkleRootTool = ToolDescription{
+		Name:        "merkle_root",
+		Description: "Compute the Merkle tree root of a list of leaves. Hashes are hex encoded.",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"required":   []string{"leaves"},
+			"properties": merkleLeavesProperties,
+		},
+	}
+	MerkleProofTool = ToolDescription{
+		Name:        "merkle_proof",
+		Description: "Compute the inclusion proof (audit path) of one leaf in a Merkle tree. Returns the root, the leaf hash and the sibling hashes from the leaf up to the root.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"leaves", "index"},
+			"properties": withProperties(merkleLeavesProperties, map[string]interface{}{
+				"index": map[string]interface{}{
+					"type":        "integer",
+					"description": "the zero based position of the leaf, after sorting when sort_leaves is set",
+					"minimum":     0,
+				},
+			}),
+		},
+	}
+	MerkleVerifyTool = ToolDescription{
+		Name:        "merkle_verify",
+		Description: "Verify a Merkle inclusion proof for a leaf against an expected root.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"leaf", "proof", "root"},
+			"properties": withProperties(merkleProperties, map[string]interface{}{
+				"leaf": map[string]interface{}{
+					"type":        "string",
+					"description": "the leaf, or its hash when hash_leaves is false",
+				},
+				"proof": map[string]interface{}{
+					"type":        "array",
+					"description": "the hex sibling hashes from the leaf up to the root",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"root": map[string]interface{}{
+					"type":        "string",
+					"description": "the expected hex root",
+				},
+				"index": map[string]interface{}{
+					"type":        "integer",
+					"description": "the leaf index, required for rfc6962",
+					"minimum":     0,
+				},
+				"tree_size": map[string]interface{}{
+					"type":        "integer",
+					"description": "the number of leaves in the tree, required for rfc6962",
+					"minimum":     1,
+				},
+			}),
+		},
+	}
+)
+
+// MerkleRoot is returned by merkle_root.
+type MerkleRoot struct {
+	Root       string   `json:"root"`
+	TreeSize   int      `json:"tree_size"`
+	LeafHashes []string `json:"leaf_hashes"`
+}
+
+// MerkleProof is returned by merkle_proof.
+type MerkleProof struct {
+	Root     string   `json:"root"`
+	Index    int      `json:"index"`
+	TreeSize int      `json:"tree_size"`
+	LeafHash string   `json:"leaf_hash"`
+	Proof    []string `json:"proof"`
+}
+
+// MerkleVerification is returned by merkle_verify.
+type MerkleVerification struct {
+	Valid        bool   `json:"valid"`
+	ComputedRoot string `json:"computed_root,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// merkleTree computes roots and proofs over leaf hashes for one hash
+// function and pairing convention.
+type merkleTree struct {
+	newHash    func() hash.Hash
+	convention string
+	hashLeaves bool
+}
+
+func newMerkleTree(args map[string]interface{}) (*merkleTree, error) {
+	t := &merkleTree{newHash: sha256.New, convention: "rfc6962", hashLeaves: true}
+	if hashVal, exists := args["hash"].(string); exists && hashVal != "" {
+		switch hashVal {
+		case "sha256":
+		case "keccak256":
+			t.newHash = sha3.NewLegacyKeccak256
+		default:
+			return nil, fmt.Errorf("unsupported hash %q, expected sha256 or keccak256", hashVal)
+		}
+	}
+	if conventionVal, exists := args["convention"].(string); exists && conventionVal != "" {
+		if conventionVal != "rfc6962" && conventionVal != "sorted-pair" {
+			return nil, fmt.Errorf("unsupported convention %q, expected rfc6962 or sorted-pair", conventionVal)
+		}
+		t.convention = conventionVal
+	}
+	if hashLeavesVal, exists := args["hash_leaves"].(bool); exists {
+		t.hashLeaves = hashLeavesVal
+	}
+	return t, nil
+}
+
+func (t *merkleTree) sum(parts ...[]byte) []byte {
+	h := t.newHash()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+// leafHash returns the hash of a leaf, or the leaf itself when it is already
+// a leaf hash.
+func (t *merkleTree) leafHash(leaf []byte) ([]byte, error) {
+	if !t.hashLeaves {
+		if len(leaf) != t.newHash().Size() {
+			return nil, fmt.Errorf("leaf hashes must be %d bytes, got %d", t.newHash().Size(), len(leaf))
+		}
+		return leaf, nil
+	}
+	if t.convention == "rfc6962" {
+		return t.sum([]byte{0x00}, leaf), nil
+	}
+	return t.sum(leaf), nil
+}
+
+func (t *merkleTree) node(left, right []byte) []byte {
+	if t.convention == "rfc6962" {
+		return t.sum([]byte{0x01}, left, right)
+	}
+	if bytes.Compare(left, right) > 0 {
+		left, right = right, left
+	}
+	return t.sum(left, right)
+}
+
+// splitPoint returns the largest power of two smaller than n.
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// root returns the root over leaf hashes. For rfc6962 this is MTH from
+// RFC 6962 section 2.1.
+func (t *merkleTree) root(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		return t.sum()
+	}
+	if t.convention == "rfc6962" {
+		if len(leaves) == 1 {
+			return leaves[0]
+		}
+		k := splitPoint(len(leaves))
+		return t.node(t.root(leaves[:k]), t.root(leaves[k:]))
+	}
+
+	level := leaves
+	for len(level) > 1 {
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, t.node(level[i], level[i+1]))
+		}
+		level = next
+	}
+	return level[0]
+}
+
+// proof returns the sibling hashes needed to recompute the root from the
+// leaf at index m. For rfc6962 this is PATH from RFC 6962 section 2.1.1.
+func (t *merkleTree) proof(m int, leaves [][]byte) [][]byte {
+	if t.convention == "rfc6962" {
+		if len(leaves) <= 1 {
+			return nil
+		}
+		k := splitPoint(len(leaves))
+		if m < k {
+			return append(t.proof(m, leaves[:k]), t.root(leaves[k:]))
+		}
+		return append(t.proof(m-k, leaves[k:]), t.root(leaves[:k]))
+	}
+
+	var path [][]byte
+	level := leaves
+	for len(level) > 1 {
+		sibling := m ^ 1
+		if sibling < len(level) {
+			path = append(path, level[sibling])
+		}
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, t.node(level[i], level[i+1]))
+		}
+		level = next
+		m /= 2
+	}
+	return path
+}
+
+// rootFromProof recomputes the root from a leaf hash and its proof. For
+// rfc6962 it follows RFC 9162 section 2.1.3.2.
+func (t *merkleTree) rootFromProof(leaf []byte, proof [][]byte, index, size int) ([]byte, error) {
+	r := leaf
+	if t.convention != "rfc6962" {
+		for _, p := range proof {
+			r = t.node(r, p)
+		}
+		return r, nil
+	}
+
+	if index >= size {
+		return nil, errors.New("index must be less than tree_size")
+	}
+	fn, sn := index, size-1
+	for _, p := range proof {
+		if sn == 0 {
+			return nil, errors.New("proof is longer than the tree is deep")
+		}
+		if fn&1 == 1 || fn == sn {
+			r = t.node(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = t.node(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return nil, errors.New("proof is shorter than the tree is deep")
+	}
+	return r, nil
+}
+
+// decodeHash decodes a hex hash, with or without a 0x prefix.
+func decodeHash(name, value string, size int) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(value), "0x"))
+	if err != nil || len(b) != size {
+		return nil, fmt.Errorf("%s must be a %d byte hex hash", name, size)
+	}
+	return b, nil
+}
+
+func merkleLeafEncoding(args map[string]interface{}) string {
+	if encodingVal, exists := args["leaf_encoding"].(string); exists && encodingVal != "" {
+		return encodingVal
+	}
+	return "utf8"
+}
+
+// merkleLeaves decodes and hashes the leaves argument.
+func merkleLeaves(t *merkleTree, args map[string]interface{}) ([][]byte, error) {
+	list, ok := args["leaves"].([]interface{})
+	if !ok {
+		return nil, errors.New("leaves must be provided")
+	}
+	encoding := merkleLeafEncoding(args)
+	if !t.hashLeaves && encoding == "utf8" {
+		encoding = "hex"
+	}
+
+	leaves := make([][]byte, len(list))
+	for i, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("leaves[%d] must be a string", i)
+		}
+		if encoding == "hex" {
+			s = strings.TrimPrefix(s, "0x")
+		}
+		leaf, err := decodeInput(s, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("leaves[%d]: %w", i, err)
+		}
+		if leaves[i], err = t.leafHash(leaf); err != nil {
+			return nil, fmt.Errorf("leaves[%d]: %w", i, err)
+		}
+	}
+	if sortLeaves, _ := args["sort_leaves"].(bool); sortLeaves {
+		sort.Slice(leaves, func(i, j int) bool {
+			return bytes.Compare(leaves[i], leaves[j]) < 0
+		})
+	}
+	return leaves, nil
+}
+
+func hexHashes(hashes [][]byte) []string {
+	out := make([]string, len(hashes))
+	for i, h := range hashes {
+		out[i] = hex.EncodeToString(h)
+	}
+	return out
+}
+
+func runMerkleRoot(args map[string]interface{}) (CallToolResult, error) {
+	t, err := newMerkleTree(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	leaves, err := merkleLeaves(t, args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	return jsonResult(MerkleRoot{
+		Root:       hex.EncodeToString(t.root(leaves)),
+		TreeSize:   len(leaves),
+		LeafHashes: hexHashes(leaves),
+	})
+}
+
+func runMerkleProof(args map[string]interface{}) (CallToolResult, error) {
+	t, err := newMerkleTree(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	leaves, err := merkleLeaves(t, args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if len(leaves) == 0 {
+		return CallToolResult{}, errors.New("leaves must not be empty")
+	}
+	index, err := intArg(args, "index", -1, 0, len(leaves)-1)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if index < 0 {
+		return CallToolResult{}, errors.New("index must be provided")
+	}
+
+	return jsonResult(MerkleProof{
+		Root:     hex.EncodeToString(t.root(leaves)),
+		Index:    index,
+		TreeSize: len(leaves),
+		LeafHash: hex.EncodeToString(leaves[index]),
+		Proof:    hexHashes(t.proof(index, leaves)),
+	})
+}
+
+func runMerkleVerify(args map[string]interface{}) (CallToolResult, error) {
+	t, err := newMerkleTree(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	size := t.newHash().Size()
+
+	leafArg, ok := args["leaf"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("leaf must be provided")
+	}
+	var leaf []byte
+	if encoding := merkleLeafEncoding(args); t.hashLeaves {
+		if encoding == "hex" {
+			leafArg = strings.TrimPrefix(leafArg, "0x")
+		}
+		leaf, err = decodeInput(leafArg, encoding)
+	} else {
+		leaf, err = decodeHash("leaf", leafArg, size)
+	}
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if leaf, err = t.leafHash(leaf); err != nil {
+		return CallToolResult{}, err
+	}
+
+	rootArg, _ := args["root"].(string)
+	root, err := decodeHash("root", rootArg, size)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	proofArg, ok := args["proof"].([]interface{})
+	if !ok {
+		return CallToolResult{}, errors.New("proof must be provided")
+	}
+	proof := make([][]byte, len(proofArg))
+	for i, item := range proofArg {
+		s, _ := item.(string)
+		if proof[i], err = decodeHash(fmt.Sprintf("proof[%d]", i), s, size); err != nil {
+			return CallToolResult{}, err
+		}
+	}
+
+	var index, treeSize int
+	if t.convention == "rfc6962" {
+		indexVal, hasIndex := args["index"].(float64)
+		sizeVal, hasSize := args["tree_size"].(float64)
+		if !hasIndex || !hasSize {
+			return CallToolResult{}, errors.New("index and tree_size must be provided for rfc6962 proofs")
+		}
+		index, treeSize = int(indexVal), int(sizeVal)
+	}
+
+	computed, err := t.rootFromProof(leaf, proof, index, treeSize)
+	if err != nil {
+		return jsonResult(MerkleVerification{Reason: err.Error()})
+	}
+	result := MerkleVerification{
+		Valid:        bytes.Equal(computed, root),
+		ComputedRoot: hex.EncodeToString(computed),
+	}
+	if !result.Valid {
+		result.Reason = "computed root does not match"
+	}
+	return jsonResult(result)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// rfc6962Leaves and the roots and audit paths below are the test vectors of
+// the Certificate Transparency implementations.
+var rfc6962Leaves = []interface{}{"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f"}
+
+var rfc6962Roots = []string{
+	"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+	"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+	"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+	"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+	"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+	"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+	"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+}
+
+var rfc6962Proofs = []struct {
+	index, size int
+	proof       []string
+}{
+	{0, 8, []string{
+		"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+		"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+		"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+	}},
+	{5, 8, []string{
+		"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+		"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	}},
+	{2, 3, []string{
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+	}},
+	{1, 5, []string{
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+		"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+	}},
+	{6, 7, []string{
+		"0ebc5d3437fbe2db158b9f126a1d118e308181031d0a949f8dededebc558ef6a",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	}},
+	{0, 1, []string{}},
+}
+
+func TestMerkleRootRFC6962(t *testing.T) {
+	for size, want := range rfc6962Roots {
+		result, err := runMerkleRoot(map[string]interface{}{
+			"leaves":        rfc6962Leaves[:size+1],
+			"leaf_encoding": "hex",
+		})
+
+		var got MerkleRoot
+		resultJSON(t, result, err, &got)
+		if got.Root != want || got.TreeSize != size+1 {
+			t.Errorf("merkle_root() of %d leaves = %s, want %s", size+1, got.Root, want)
+		}
+	}
+}
+
+func TestMerkleProofRFC6962(t *testing.T) {
+	for _, tt := range rfc6962Proofs {
+		result, err := runMerkleProof(map[string]interface{}{
+			"leaves":        rfc6962Leaves[:tt.size],
+			"leaf_encoding": "hex",
+			"index":         float64(tt.index),
+		})
+
+		var got MerkleProof
+		resultJSON(t, result, err, &got)
+		if !reflect.DeepEqual(got.Proof, tt.proof) || got.Root != rfc6962Roots[tt.size-1] {
+			t.Errorf("merkle_proof() of leaf %d in %d = %v under %s, want %v under %s", tt.index, tt.size, got.Proof, got.Root, tt.proof, rfc6962Roots[tt.size-1])
+		}
+	}
+}
+
+func TestMerkleVerifyRFC6962(t *testing.T) {
+	for _, tt := range rfc6962Proofs {
+		proof := make([]interface{}, len(tt.proof))
+		for i, p := range tt.proof {
+			proof[i] = p
+		}
+		args := map[string]interface{}{
+			"leaf":          rfc6962Leaves[tt.index],
+			"leaf_encoding": "hex",
+			"proof":         proof,
+			"root":          rfc6962Roots[tt.size-1],
+			"index":         float64(tt.index),
+			"tree_size":     float64(tt.size),
+		}
+
+		var got MerkleVerification
+		result, err := runMerkleVerify(args)
+		resultJSON(t, result, err, &got)
+		if !got.Valid {
+			t.Errorf("merkle_verify() of leaf %d in %d = %+v, want valid", tt.index, tt.size, got)
+		}
+
+		// the same proof must not hold for a neighbouring index
+		args["index"] = float64((tt.index + 1) % tt.size)
+		result, err = runMerkleVerify(args)
+		got = MerkleVerification{}
+		resultJSON(t, result, err, &got)
+		if tt.size > 1 && got.Valid {
+			t.Errorf("merkle_verify() of leaf %d in %d at index %v = valid, want invalid", tt.index, tt.size, args["index"])
+		}
+	}
+}
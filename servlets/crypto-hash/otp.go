@@ -0,0 +1,425 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var otpProperties = map[string]interface{}{
+	"uri": map[string]interface{}{
+		"type":        "string",
+		"description": "an otpauth:// URI, as encoded in enrollment QR codes. Other arguments override its parameters. Either uri or secret must be provided",
+	},
+	"secret": map[string]interface{}{
+		"type":        "string",
+		"description": "the shared secret, interpreted according to `secret_encoding`",
+	},
+	"secret_encoding": map[string]interface{}{
+		"type":        "string",
+		"description": "how `secret` is encoded. Authenticator apps use base32 (default: base32)",
+		"enum":        []string{"base32", "hex", "base64", "utf8"},
+		"default":     "base32",
+	},
+	"algorithm": map[string]interface{}{
+		"type":        "string",
+		"description": "the HMAC hash function (default: sha1)",
+		"enum":        []string{"sha1", "sha256", "sha512"},
+		"default":     "sha1",
+	},
+	"digits": map[string]interface{}{
+		"type":        "integer",
+		"description": "number of digits in the code (default: 6)",
+		"minimum":     6,
+		"maximum":     8,
+		"default":     6,
+	},
+	"period": map[string]interface{}{
+		"type":        "integer",
+		"description": "TOTP time step in seconds (default: 30)",
+		"minimum":     1,
+		"maximum":     3600,
+		"default":     30,
+	},
+	"time": map[string]interface{}{
+		"type":        "string",
+		"description": "the time to compute the code for, as unix seconds or RFC 3339. Defaults to now",
+	},
+	"counter": map[string]interface{}{
+		"type":        "integer",
+		"description": "HOTP counter. When set, an RFC 4226 counter-based code is used instead of TOTP",
+		"minimum":     0,
+	},
+}
+
+var (
+	TOTPGenerateTool = ToolDescription{
+		Name:        "totp_generate",
+		Description: "Generate an RFC 6238 TOTP code (or an RFC 4226 HOTP code when `counter` is set) from a shared secret or otpauth:// URI. Returns the code and how long it remains valid.",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": otpProperties,
+		},
+	}
+	TOTPVerifyTool = ToolDescription{
+		Name:        "totp_verify",
+		Description: "Verify a TOTP or HOTP code against a shared secret or otpauth:// URI, allowing for clock skew. Returns whether the code is valid and the time-step drift it matched at.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"code"},
+			"properties": withProperties(otpProperties, map[string]interface{}{
+				"code": map[string]interface{}{
+					"type":        "string",
+					"description": "the code to verify",
+				},
+				"skew": map[string]interface{}{
+					"type":        "integer",
+					"description": "number of time steps accepted either side of the current one. For HOTP, the number of counters to look ahead (default: 1)",
+					"minimum":     0,
+					"maximum":     10,
+					"default":     1,
+				},
+			}),
+		},
+	}
+	OTPAuthParseTool = ToolDescription{
+		Name:        "otpauth_parse",
+		Description: "Parse an otpauth:// URI (the payload of a 2FA enrollment QR code) into its type, issuer, account, secret, algorithm, digits and period or counter.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"uri"},
+			"properties": map[string]interface{}{
+				"uri": map[string]interface{}{
+					"type":        "string",
+					"description": "the otpauth:// URI",
+				},
+			},
+		},
+	}
+)
+
+// OTPAuth describes a one-time password generator, as encoded by an
+// otpauth:// URI.
+type OTPAuth struct {
+	Type      string  `json:"type"`
+	Label     string  `json:"label,omitempty"`
+	Issuer    string  `json:"issuer,omitempty"`
+	Account   string  `json:"account,omitempty"`
+	Secret    string  `json:"secret"`
+	Algorithm string  `json:"algorithm"`
+	Digits    int     `json:"digits"`
+	Period    int     `json:"period,omitempty"`
+	Counter   *uint64 `json:"counter,omitempty"`
+}
+
+// OTPCode is returned by totp_generate.
+type OTPCode struct {
+	Code       string `json:"code"`
+	Type       string `json:"type"`
+	Counter    uint64 `json:"counter"`
+	Period     int    `json:"period,omitempty"`
+	ExpiresIn  int64  `json:"expires_in,omitempty"`
+	ValidUntil string `json:"valid_until,omitempty"`
+}
+
+// OTPVerification is returned by totp_verify.
+type OTPVerification struct {
+	Valid   bool    `json:"valid"`
+	Type    string  `json:"type"`
+	Counter *uint64 `json:"counter,omitempty"`
+	Drift   *int64  `json:"drift,omitempty"`
+	Reason  string  `json:"reason,omitempty"`
+}
+
+var otpBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// decodeOTPSecret decodes a base32 secret, tolerating lower case, spaces and
+// padding as shown by most enrollment pages.
+func decodeOTPSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "=", "").Replace(secret))
+	return otpBase32.DecodeString(s)
+}
+
+// parseOTPAuthURI parses an otpauth://TYPE/LABEL?PARAMETERS URI.
+func parseOTPAuthURI(uri string) (OTPAuth, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return OTPAuth{}, fmt.Errorf("invalid uri: %w", err)
+	}
+	if u.Scheme != "otpauth" {
+		return OTPAuth{}, errors.New("uri must use the otpauth scheme")
+	}
+
+	auth := OTPAuth{Type: strings.ToLower(u.Host), Algorithm: "sha1", Digits: 6}
+	if auth.Type != "totp" && auth.Type != "hotp" {
+		return OTPAuth{}, fmt.Errorf("unsupported otpauth type %q, expected totp or hotp", u.Host)
+	}
+
+	auth.Label = strings.TrimPrefix(u.Path, "/")
+	if issuer, account, found := strings.Cut(auth.Label, ":"); found {
+		auth.Issuer = strings.TrimSpace(issuer)
+		auth.Account = strings.TrimSpace(account)
+	} else {
+		auth.Account = auth.Label
+	}
+
+	q := u.Query()
+	if issuer := q.Get("issuer"); issuer != "" {
+		auth.Issuer = issuer
+	}
+	auth.Secret = strings.ToUpper(q.Get("secret"))
+	if auth.Secret == "" {
+		return OTPAuth{}, errors.New("uri is missing the secret parameter")
+	}
+	if _, err := decodeOTPSecret(auth.Secret); err != nil {
+		return OTPAuth{}, fmt.Errorf("uri secret is not valid base32: %w", err)
+	}
+	if algorithm := q.Get("algorithm"); algorithm != "" {
+		auth.Algorithm = strings.ToLower(algorithm)
+	}
+	if digits := q.Get("digits"); digits != "" {
+		if auth.Digits, err = strconv.Atoi(digits); err != nil {
+			return OTPAuth{}, fmt.Errorf("invalid digits %q", digits)
+		}
+	}
+
+	switch auth.Type {
+	case "totp":
+		auth.Period = 30
+		if period := q.Get("period"); period != "" {
+			if auth.Period, err = strconv.Atoi(period); err != nil {
+				return OTPAuth{}, fmt.Errorf("invalid period %q", period)
+			}
+		}
+	case "hotp":
+		counter, err := strconv.ParseUint(q.Get("counter"), 10, 64)
+		if err != nil {
+			return OTPAuth{}, errors.New("hotp uri must have a numeric counter parameter")
+		}
+		auth.Counter = &counter
+	}
+	return auth, validateOTPAuth(auth)
+}
+
+func validateOTPAuth(auth OTPAuth) error {
+	switch auth.Algorithm {
+	case "sha1", "sha256", "sha512":
+	default:
+		return fmt.Errorf("unsupported algorithm %q, expected sha1, sha256 or sha512", auth.Algorithm)
+	}
+	if auth.Digits < 6 || auth.Digits > 8 {
+		return errors.New("digits must be between 6 and 8")
+	}
+	if auth.Type == "totp" && (auth.Period < 1 || auth.Period > 3600) {
+		return errors.New("period must be between 1 and 3600")
+	}
+	return nil
+}
+
+// otpConfig combines the uri argument with explicit overrides and returns
+// the generator description and its decoded secret.
+func otpConfig(args map[string]interface{}) (OTPAuth, []byte, error) {
+	auth := OTPAuth{Type: "totp", Algorithm: "sha1", Digits: 6, Period: 30}
+	var secret []byte
+
+	if uri, exists := args["uri"].(string); exists && uri != "" {
+		parsed, err := parseOTPAuthURI(uri)
+		if err != nil {
+			return OTPAuth{}, nil, err
+		}
+		auth = parsed
+		secret, _ = decodeOTPSecret(auth.Secret)
+	}
+
+	if secretVal, exists := args["secret"].(string); exists && secretVal != "" {
+		encoding := "base32"
+		if encodingVal, exists := args["secret_encoding"].(string); exists && encodingVal != "" {
+			encoding = encodingVal
+		}
+		var err error
+		if encoding == "base32" {
+			secret, err = decodeOTPSecret(secretVal)
+		} else {
+			secret, err = decodeInput(secretVal, encoding)
+		}
+		if err != nil {
+			return OTPAuth{}, nil, fmt.Errorf("invalid secret: %w", err)
+		}
+		auth.Secret = otpBase32.EncodeToString(secret)
+	}
+	if len(secret) == 0 {
+		return OTPAuth{}, nil, errors.New("either uri or secret must be provided")
+	}
+
+	if algorithmVal, exists := args["algorithm"].(string); exists && algorithmVal != "" {
+		auth.Algorithm = strings.ToLower(algorithmVal)
+	}
+	if digitsVal, exists := args["digits"].(float64); exists {
+		auth.Digits = int(digitsVal)
+	}
+	if periodVal, exists := args["period"].(float64); exists {
+		auth.Period = int(periodVal)
+	}
+	if counterVal, exists := args["counter"].(float64); exists {
+		if counterVal < 0 {
+			return OTPAuth{}, nil, errors.New("counter must not be negative")
+		}
+		counter := uint64(counterVal)
+		auth.Type = "hotp"
+		auth.Counter = &counter
+	}
+	if auth.Type == "hotp" {
+		auth.Period = 0
+	}
+
+	return auth, secret, validateOTPAuth(auth)
+}
+
+// otpTime returns the time argument, or the current time.
+func otpTime(args map[string]interface{}) (time.Time, error) {
+	switch t := args["time"].(type) {
+	case nil:
+		return time.Now(), nil
+	case float64:
+		return time.Unix(int64(t), 0), nil
+	case string:
+		if t == "" {
+			return time.Now(), nil
+		}
+		if secs, err := strconv.ParseInt(t, 10, 64); err == nil {
+			return time.Unix(secs, 0), nil
+		}
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return time.Time{}, errors.New("time must be unix seconds or RFC 3339")
+		}
+		return parsed, nil
+	default:
+		return time.Time{}, errors.New("time must be unix seconds or RFC 3339")
+	}
+}
+
+// hotp computes the RFC 4226 code for counter.
+func hotp(secret []byte, counter uint64, algorithm string, digits int) string {
+	mac := hmac.New(func() hash.Hash {
+		h, _ := newDigest(algorithm, 0)
+		return h
+	}, secret)
+	binary.Write(mac, binary.BigEndian, counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
+
+func runTOTPGenerate(args map[string]interface{}) (CallToolResult, error) {
+	auth, secret, err := otpConfig(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	if auth.Type == "hotp" {
+		return jsonResult(OTPCode{
+			Code:    hotp(secret, *auth.Counter, auth.Algorithm, auth.Digits),
+			Type:    auth.Type,
+			Counter: *auth.Counter,
+		})
+	}
+
+	now, err := otpTime(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	counter := uint64(now.Unix()) / uint64(auth.Period)
+	validUntil := time.Unix(int64(counter+1)*int64(auth.Period), 0).UTC()
+
+	return jsonResult(OTPCode{
+		Code:       hotp(secret, counter, auth.Algorithm, auth.Digits),
+		Type:       auth.Type,
+		Counter:    counter,
+		Period:     auth.Period,
+		ExpiresIn:  validUntil.Unix() - now.Unix(),
+		ValidUntil: validUntil.Format(time.RFC3339),
+	})
+}
+
+func runTOTPVerify(args map[string]interface{}) (CallToolResult, error) {
+	code, ok := args["code"].(string)
+	if !ok || code == "" {
+		return CallToolResult{}, errors.New("code must be provided")
+	}
+	code = strings.ReplaceAll(code, " ", "")
+
+	auth, secret, err := otpConfig(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	skew, err := intArg(args, "skew", 1, 0, 10)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	result := OTPVerification{Type: auth.Type}
+	if len(code) != auth.Digits {
+		result.Reason = fmt.Sprintf("code must have %d digits", auth.Digits)
+		return jsonResult(result)
+	}
+
+	var base uint64
+	var from int64
+	if auth.Type == "hotp" {
+		base = *auth.Counter
+	} else {
+		now, err := otpTime(args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		base = uint64(now.Unix()) / uint64(auth.Period)
+		from = -int64(skew)
+	}
+
+	// check every candidate so the time taken does not reveal the drift
+	for drift := from; drift <= int64(skew); drift++ {
+		if drift < 0 && uint64(-drift) > base {
+			continue
+		}
+		counter := uint64(int64(base) + drift)
+		expected := hotp(secret, counter, auth.Algorithm, auth.Digits)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 && !result.Valid {
+			d := drift
+			result.Valid = true
+			result.Counter = &counter
+			result.Drift = &d
+		}
+	}
+	if !result.Valid {
+		result.Reason = "code does not match within the skew window"
+	}
+	return jsonResult(result)
+}
+
+func runOTPAuthParse(args map[string]interface{}) (CallToolResult, error) {
+	uri, ok := args["uri"].(string)
+	if !ok || uri == "" {
+		return CallToolResult{}, errors.New("uri must be provided")
+	}
+	auth, err := parseOTPAuthURI(uri)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	return jsonResult(auth)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// rfc6238Seeds are the secrets of the RFC 6238 appendix B test vectors.
+var rfc6238Seeds = map[string]string{
+	"sha1":   "12345678901234567890",
+	"sha256": "12345678901234567890123456789012",
+	"sha512": strings.Repeat("1234567890", 6) + "1234",
+}
+
+func TestTOTPGenerateRFC6238(t *testing.T) {
+	tests := []struct {
+		time  float64
+		codes map[string]string
+	}{
+		{59, map[string]string{"sha1": "94287082", "sha256": "46119246", "sha512": "90693936"}},
+		{1111111109, map[string]string{"sha1": "07081804", "sha256": "68084774", "sha512": "25091201"}},
+		{1111111111, map[string]string{"sha1": "14050471", "sha256": "67062674", "sha512": "99943326"}},
+		{1234567890, map[string]string{"sha1": "89005924", "sha256": "91819424", "sha512": "93441116"}},
+		{2000000000, map[string]string{"sha1": "69279037", "sha256": "90698825", "sha512": "38618901"}},
+		{20000000000, map[string]string{"sha1": "65353130", "sha256": "77737706", "sha512": "47863826"}},
+	}
+	for _, tt := range tests {
+		for algorithm, want := range tt.codes {
+			result, err := runTOTPGenerate(map[string]interface{}{
+				"secret":          rfc6238Seeds[algorithm],
+				"secret_encoding": "utf8",
+				"algorithm":       algorithm,
+				"digits":          float64(8),
+				"time":            tt.time,
+			})
+
+			var got OTPCode
+			resultJSON(t, result, err, &got)
+			if got.Code != want || got.Counter != uint64(tt.time)/30 {
+				t.Errorf("totp_generate(%s at %.0f) = %s for counter %d, want %s", algorithm, tt.time, got.Code, got.Counter, want)
+			}
+		}
+	}
+}
+
+func TestTOTPGenerateRFC4226(t *testing.T) {
+	// RFC 4226 appendix D, counters 0 to 9
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	// the same secret as base32, as an authenticator app would show it
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	for counter, code := range want {
+		result, err := runTOTPGenerate(map[string]interface{}{"secret": secret, "counter": float64(counter)})
+
+		var got OTPCode
+		resultJSON(t, result, err, &got)
+		if got.Code != code || got.Type != "hotp" {
+			t.Errorf("totp_generate(counter %d) = %s %s, want hotp %s", counter, got.Type, got.Code, code)
+		}
+	}
+}
+
+func TestTOTPVerify(t *testing.T) {
+	tests := []struct {
+		name  string
+		code  string
+		time  float64
+		skew  float64
+		valid bool
+		drift int64
+	}{
+		{name: "current step", code: "07081804", time: 1111111109, skew: 1, valid: true},
+		{name: "previous step within skew", code: "07081804", time: 1111111109 + 30, skew: 1, valid: true, drift: -1},
+		{name: "next step within skew", code: "07081804", time: 1111111109 - 30, skew: 1, valid: true, drift: 1},
+		{name: "outside the skew", code: "07081804", time: 1111111109 + 60, skew: 1},
+		{name: "no skew", code: "07081804", time: 1111111109 + 30, skew: 0},
+		{name: "wrong code", code: "07081805", time: 1111111109, skew: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runTOTPVerify(map[string]interface{}{
+				"uri":  "otpauth://totp/Example:alice@example.com?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&issuer=Example&digits=8",
+				"code": tt.code,
+				"time": tt.time,
+				"skew": tt.skew,
+			})
+
+			var got OTPVerification
+			resultJSON(t, result, err, &got)
+			if got.Valid != tt.valid {
+				t.Fatalf("totp_verify() = %+v, want valid %t", got, tt.valid)
+			}
+			if tt.valid && (got.Drift == nil || *got.Drift != tt.drift) {
+				t.Errorf("totp_verify() drift = %v, want %d", got.Drift, tt.drift)
+			}
+		})
+	}
+}
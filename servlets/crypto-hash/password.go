@@ -0,0 +1,546 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	PasswordVerifyTool = ToolDescription{
+		Name:        "password_verify",
+		Description: "Check a password against an encoded password hash. The algorithm is detected from the hash: bcrypt ($2a$, $2b$, $2y$), argon2id/argon2i ($argon2id$...), scrypt ($scrypt$ln=...,r=...,p=...$...) and PBKDF2 in PHC format ($pbkdf2-sha256$i=...$...).",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"password", "hash"},
+			"properties": map[string]interface{}{
+				"password": map[string]interface{}{
+					"type":        "string",
+					"description": "the password to check",
+				},
+				"hash": map[string]interface{}{
+					"type":        "string",
+					"description": "the encoded password hash",
+				},
+			},
+		},
+	}
+	Argon2idTool = ToolDescription{
+		Name:        "argon2id",
+		Description: "Hash a password using argon2id (recommended for passwords). Returns the PHC string `$argon2id$v=19$m=...,t=...,p=...$salt$hash`",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"password"},
+			"properties": map[string]interface{}{
+				"password": map[string]interface{}{
+					"type":        "string",
+					"description": "the password to hash",
+				},
+				"memory": map[string]interface{}{
+					"type":        "integer",
+					"description": "memory cost in KiB (8-1048576, default: 19456)",
+					"minimum":     8,
+					"maximum":     1048576,
+					"default":     19456,
+				},
+				"iterations": map[string]interface{}{
+					"type":        "integer",
+					"description": "number of passes over the memory (1-100, default: 2)",
+					"minimum":     1,
+					"maximum":     100,
+					"default":     2,
+				},
+				"parallelism": map[string]interface{}{
+					"type":        "integer",
+					"description": "degree of parallelism (1-255, default: 1)",
+					"minimum":     1,
+					"maximum":     255,
+					"default":     1,
+				},
+				"salt_length": saltLengthProperty,
+				"key_length":  keyLengthProperty,
+			},
+		},
+	}
+	ScryptTool = ToolDescription{
+		Name:        "scrypt",
+		Description: "Hash a password using scrypt. Returns the PHC string `$scrypt$ln=...,r=...,p=...$salt$hash`",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"password"},
+			"properties": map[string]interface{}{
+				"password": map[string]interface{}{
+					"type":        "string",
+					"description": "the password to hash",
+				},
+				"ln": map[string]interface{}{
+					"type":        "integer",
+					"description": "CPU/memory cost as a power of two, N = 2^ln (1-20, default: 15)",
+					"minimum":     1,
+					"maximum":     20,
+					"default":     15,
+				},
+				"r": map[string]interface{}{
+					"type":        "integer",
+					"description": "block size (1-32, default: 8). scrypt uses 128 * r * 2^ln bytes of memory, at most 1 GiB",
+					"minimum":     1,
+					"maximum":     32,
+					"default":     8,
+				},
+				"p": map[string]interface{}{
+					"type":        "integer",
+					"description": "parallelization (1-16, default: 1)",
+					"minimum":     1,
+					"maximum":     16,
+					"default":     1,
+				},
+				"salt_length": saltLengthProperty,
+				"key_length":  keyLengthProperty,
+			},
+		},
+	}
+	PBKDF2Tool = ToolDescription{
+		Name:        "pbkdf2",
+		Description: "Hash a password using PBKDF2. Returns the PHC string `$pbkdf2-<algorithm>$i=...,l=...$salt$hash`",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"password"},
+			"properties": map[string]interface{}{
+				"password": map[string]interface{}{
+					"type":        "string",
+					"description": "the password to hash",
+				},
+				"algorithm": map[string]interface{}{
+					"type":        "string",
+					"description": "the HMAC hash function (default: sha256)",
+					"enum":        []string{"sha1", "sha256", "sha512"},
+					"default":     "sha256",
+				},
+				"iterations": map[string]interface{}{
+					"type":        "integer",
+					"description": "number of iterations (1000-10000000, default: 600000)",
+					"minimum":     1000,
+					"maximum":     10000000,
+					"default":     600000,
+				},
+				"salt_length": saltLengthProperty,
+				"key_length":  keyLengthProperty,
+			},
+		},
+	}
+)
+
+var saltLengthProperty = map[string]interface{}{
+	"type":        "integer",
+	"description": "length of the random salt in bytes (8-64, default: 16)",
+	"minimum":     8,
+	"maximum":     64,
+	"default":     16,
+}
+
+var keyLengthProperty = map[string]interface{}{
+	"type":        "integer",
+	"description": "length of the derived hash in bytes (16-128, default: 32)",
+	"minimum":     16,
+	"maximum":     128,
+	"default":     32,
+}
+
+// PasswordVerification is returned by password_verify.
+type PasswordVerification struct {
+	Valid      bool              `json:"valid"`
+	Algorithm  string            `json:"algorithm"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// maxBcryptVerifyCost is the highest bcrypt cost password_verify accepts.
+const maxBcryptVerifyCost = 16
+
+// phcEncoding is the unpadded base64 alphabet used by PHC strings.
+var phcEncoding = base64.RawStdEncoding
+
+// intArg reads an optional integer argument, falling back to def when it is
+// absent and rejecting values outside [min, max].
+func intArg(args map[string]interface{}, name string, def, min, max int) (int, error) {
+	val, exists := args[name].(float64)
+	if !exists {
+		return def, nil
+	}
+	v := int(val)
+	if v < min || v > max {
+		return 0, fmt.Errorf("%s must be between %d and %d", name, min, max)
+	}
+	return v, nil
+}
+
+func randomSalt(args map[string]interface{}) ([]byte, error) {
+	saltLength, err := intArg(args, "salt_length", 16, 8, 64)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return salt, nil
+}
+
+func runArgon2id(args map[string]interface{}) (CallToolResult, error) {
+	pwd, ok := args["password"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("password must be provided")
+	}
+
+	memory, err := intArg(args, "memory", 19456, 8, 1048576)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	iterations, err := intArg(args, "iterations", 2, 1, 100)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	parallelism, err := intArg(args, "parallelism", 1, 1, 255)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if memory < 8*parallelism {
+		return CallToolResult{}, errors.New("memory must be at least 8 KiB per unit of parallelism")
+	}
+	keyLength, err := intArg(args, "key_length", 32, 16, 128)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	salt, err := randomSalt(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	key := argon2.IDKey([]byte(pwd), salt, uint32(iterations), uint32(memory), uint8(parallelism), uint32(keyLength))
+	encoded := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, memory, iterations, parallelism, phcEncoding.EncodeToString(salt), phcEncoding.EncodeToString(key))
+	return textResult(encoded), nil
+}
+
+func runScrypt(args map[string]interface{}) (CallToolResult, error) {
+	pwd, ok := args["password"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("password must be provided")
+	}
+
+	ln, err := intArg(args, "ln", 15, 1, 20)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	r, err := intArg(args, "r", 8, 1, 32)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	p, err := intArg(args, "p", 1, 1, 16)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if err := checkScryptMemory(ln, r); err != nil {
+		return CallToolResult{}, err
+	}
+	keyLength, err := intArg(args, "key_length", 32, 16, 128)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	salt, err := randomSalt(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	key, err := scrypt.Key([]byte(pwd), salt, 1<<ln, r, p, keyLength)
+	if err != nil {
+		return CallToolResult{}, fmt.Errorf("scrypt error: %v", err)
+	}
+	encoded := fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s",
+		ln, r, p, phcEncoding.EncodeToString(salt), phcEncoding.EncodeToString(key))
+	return textResult(encoded), nil
+}
+
+func runPBKDF2(args map[string]interface{}) (CallToolResult, error) {
+	pwd, ok := args["password"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("password must be provided")
+	}
+
+	algorithm := "sha256"
+	if algorithmVal, exists := args["algorithm"].(string); exists && algorithmVal != "" {
+		algorithm = strings.ToLower(algorithmVal)
+	}
+	hashFunc, err := pbkdf2Hash(algorithm)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	iterations, err := intArg(args, "iterations", 600000, 1000, 10000000)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	keyLength, err := intArg(args, "key_length", 32, 16, 128)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	salt, err := randomSalt(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	key := pbkdf2.Key([]byte(pwd), salt, iterations, keyLength, hashFunc)
+	encoded := fmt.Sprintf("$pbkdf2-%s$i=%d,l=%d$%s$%s",
+		algorithm, iterations, keyLength, phcEncoding.EncodeToString(salt), phcEncoding.EncodeToString(key))
+	return textResult(encoded), nil
+}
+
+// checkScryptMemory rejects parameters needing more than 1 GiB, the same
+// limit as the argon2id memory cost. scrypt uses 128 * r * 2^ln bytes.
+func checkScryptMemory(ln, r int) error {
+	if 128*r<<ln > 1<<30 {
+		return errors.New("ln and r need more than 1 GiB of memory")
+	}
+	return nil
+}
+
+func pbkdf2Hash(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "sha1":
+		return sha1.New, nil
+	case "sha256":
+		return sha256.New, nil
+	case "sha512":
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, expected sha1, sha256 or sha512", algorithm)
+	}
+}
+
+func runPasswordVerify(args map[string]interface{}) (CallToolResult, error) {
+	pwd, ok := args["password"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("password must be provided")
+	}
+	encoded, ok := args["hash"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("hash must be provided")
+	}
+	encoded = strings.TrimSpace(encoded)
+
+	var (
+		result PasswordVerification
+		err    error
+	)
+	switch {
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		result, err = verifyBcrypt(pwd, encoded)
+	case strings.HasPrefix(encoded, "$argon2id$"), strings.HasPrefix(encoded, "$argon2i$"):
+		result, err = verifyArgon2(pwd, encoded)
+	case strings.HasPrefix(encoded, "$scrypt$"):
+		result, err = verifyScrypt(pwd, encoded)
+	case strings.HasPrefix(encoded, "$pbkdf2-"):
+		result, err = verifyPBKDF2(pwd, encoded)
+	default:
+		return CallToolResult{}, errors.New("unrecognized hash format, expected bcrypt, argon2id, scrypt or PBKDF2 (PHC format)")
+	}
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	return jsonResult(result)
+}
+
+func verifyBcrypt(pwd, encoded string) (PasswordVerification, error) {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return PasswordVerification{}, fmt.Errorf("invalid bcrypt hash: %v", err)
+	}
+	// bcrypt accepts costs up to 31, but every step doubles the work and
+	// anything above 16 would keep verification busy for minutes
+	if cost > maxBcryptVerifyCost {
+		return PasswordVerification{}, fmt.Errorf("invalid bcrypt hash: cost %d is above the maximum of %d", cost, maxBcryptVerifyCost)
+	}
+
+	result := PasswordVerification{
+		Algorithm:  "bcrypt",
+		Parameters: map[string]string{"cost": strconv.Itoa(cost)},
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(encoded), []byte(pwd))
+	if err != nil && !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return PasswordVerification{}, fmt.Errorf("bcrypt error: %v", err)
+	}
+	result.Valid = err == nil
+	return result, nil
+}
+
+// parsePHC splits a PHC string `$id[$v=version][$params]$salt$hash` into its
+// identifier, parameters, salt and hash. Salts shorter than 8 bytes and
+// hashes outside 16-128 bytes are rejected: an empty hash would otherwise
+// match any password.
+func parsePHC(encoded string) (string, map[string]string, []byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(encoded, "$"), "$")
+	if len(parts) < 3 {
+		return "", nil, nil, nil, errors.New("invalid PHC string: expected $id$params$salt$hash")
+	}
+
+	id := parts[0]
+	params := map[string]string{}
+	for _, section := range parts[1 : len(parts)-2] {
+		for _, kv := range strings.Split(section, ",") {
+			k, v, found := strings.Cut(kv, "=")
+			if !found {
+				// passlib writes the PBKDF2 round count without a key
+				k, v = "i", kv
+			}
+			params[k] = v
+		}
+	}
+
+	salt, err := decodePHCBase64(parts[len(parts)-2])
+	if err != nil {
+		return "", nil, nil, nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	key, err := decodePHCBase64(parts[len(parts)-1])
+	if err != nil {
+		return "", nil, nil, nil, fmt.Errorf("invalid hash: %w", err)
+	}
+	if len(salt) < 8 {
+		return "", nil, nil, nil, errors.New("invalid salt: must be at least 8 bytes")
+	}
+	if len(key) < 16 || len(key) > 128 {
+		return "", nil, nil, nil, errors.New("invalid hash: must be between 16 and 128 bytes")
+	}
+	return id, params, salt, key, nil
+}
+
+// decodePHCBase64 decodes unpadded base64, also accepting the "adapted"
+// alphabet passlib uses ('.' in place of '+').
+func decodePHCBase64(s string) ([]byte, error) {
+	s = strings.ReplaceAll(strings.TrimRight(s, "="), ".", "+")
+	return phcEncoding.DecodeString(s)
+}
+
+// phcInt reads a cost parameter of a PHC string, rejecting values outside
+// [min, max]. The hash comes from the caller, so the bounds are the ones the
+// hashing tools enforce to keep verification from exhausting memory or time.
+func phcInt(params map[string]string, name string, min, max int) (int, error) {
+	v, ok := params[name]
+	if !ok {
+		return 0, fmt.Errorf("missing %s parameter", name)
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s parameter %q", name, v)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("invalid %s parameter: must be between %d and %d", name, min, max)
+	}
+	return n, nil
+}
+
+func verifyArgon2(pwd, encoded string) (PasswordVerification, error) {
+	id, params, salt, key, err := parsePHC(encoded)
+	if err != nil {
+		return PasswordVerification{}, err
+	}
+	if v, ok := params["v"]; ok && v != strconv.Itoa(argon2.Version) {
+		return PasswordVerification{}, fmt.Errorf("unsupported argon2 version %s", v)
+	}
+	memory, err := phcInt(params, "m", 8, 1048576)
+	if err != nil {
+		return PasswordVerification{}, err
+	}
+	iterations, err := phcInt(params, "t", 1, 100)
+	if err != nil {
+		return PasswordVerification{}, err
+	}
+	parallelism, err := phcInt(params, "p", 1, 255)
+	if err != nil {
+		return PasswordVerification{}, err
+	}
+	if memory < 8*parallelism {
+		return PasswordVerification{}, errors.New("invalid m parameter: must be at least 8 KiB per unit of parallelism")
+	}
+
+	var derived []byte
+	if id == "argon2id" {
+		derived = argon2.IDKey([]byte(pwd), salt, uint32(iterations), uint32(memory), uint8(parallelism), uint32(len(key)))
+	} else {
+		derived = argon2.Key([]byte(pwd), salt, uint32(iterations), uint32(memory), uint8(parallelism), uint32(len(key)))
+	}
+
+	return PasswordVerification{
+		Valid:      subtle.ConstantTimeCompare(derived, key) == 1,
+		Algorithm:  id,
+		Parameters: params,
+	}, nil
+}
+
+func verifyScrypt(pwd, encoded string) (PasswordVerification, error) {
+	_, params, salt, key, err := parsePHC(encoded)
+	if err != nil {
+		return PasswordVerification{}, err
+	}
+	ln, err := phcInt(params, "ln", 1, 20)
+	if err != nil {
+		return PasswordVerification{}, err
+	}
+	r, err := phcInt(params, "r", 1, 32)
+	if err != nil {
+		return PasswordVerification{}, err
+	}
+	p, err := phcInt(params, "p", 1, 16)
+	if err != nil {
+		return PasswordVerification{}, err
+	}
+	if err := checkScryptMemory(ln, r); err != nil {
+		return PasswordVerification{}, err
+	}
+
+	derived, err := scrypt.Key([]byte(pwd), salt, 1<<ln, r, p, len(key))
+	if err != nil {
+		return PasswordVerification{}, fmt.Errorf("scrypt error: %v", err)
+	}
+
+	return PasswordVerification{
+		Valid:      subtle.ConstantTimeCompare(derived, key) == 1,
+		Algorithm:  "scrypt",
+		Parameters: params,
+	}, nil
+}
+
+func verifyPBKDF2(pwd, encoded string) (PasswordVerification, error) {
+	id, params, salt, key, err := parsePHC(encoded)
+	if err != nil {
+		return PasswordVerification{}, err
+	}
+	algorithm := strings.TrimPrefix(id, "pbkdf2-")
+	hashFunc, err := pbkdf2Hash(algorithm)
+	if err != nil {
+		return PasswordVerification{}, err
+	}
+	iterations, err := phcInt(params, "i", 1000, 10000000)
+	if err != nil {
+		return PasswordVerification{}, err
+	}
+
+	derived := pbkdf2.Key([]byte(pwd), salt, iterations, len(key), hashFunc)
+
+	return PasswordVerification{
+		Valid:      subtle.ConstantTimeCompare(derived, key) == 1,
+		Algorithm:  id,
+		Parameters: params,
+	}, nil
+}
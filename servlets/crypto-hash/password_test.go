@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPasswordVerifyKnownAnswers(t *testing.T) {
+	// the bcrypt vector is from the OpenBSD test suite, the argon2i one from
+	// the reference implementation's README; scrypt and pbkdf2 were computed
+	// with Python's hashlib
+	tests := []struct {
+		name      string
+		password  string
+		hash      string
+		algorithm string
+	}{
+		{
+			name:      "bcrypt",
+			password:  "U*U",
+			hash:      "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+			algorithm: "bcrypt",
+		},
+		{
+			name:      "argon2i",
+			password:  "password",
+			hash:      "$argon2i$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG",
+			algorithm: "argon2i",
+		},
+		{
+			name:      "scrypt",
+			password:  "password",
+			hash:      "$scrypt$ln=10,r=8,p=1$c2FsdHNhbHQ$AOLXEESCcPmf2DxU3D47ZJxp5ZTcHC0S2Mb2eFXc4tI",
+			algorithm: "scrypt",
+		},
+		{
+			name:      "pbkdf2-sha256",
+			password:  "password",
+			hash:      "$pbkdf2-sha256$i=1000,l=32$c2FsdHNhbHQ$E196ZhRPzw+wA84EjzHwJO1cv/MFJdO6C/sxmUeTYqY",
+			algorithm: "pbkdf2-sha256",
+		},
+		{
+			name:      "pbkdf2-sha256 in the adapted alphabet",
+			password:  "password",
+			hash:      "$pbkdf2-sha256$i=1000,l=32$c2FsdHNhbHQ$E196ZhRPzw.wA84EjzHwJO1cv/MFJdO6C/sxmUeTYqY",
+			algorithm: "pbkdf2-sha256",
+		},
+		{
+			name:      "pbkdf2-sha512",
+			password:  "password",
+			hash:      "$pbkdf2-sha512$i=1000$c2FsdHNhbHQ$Q6v4xwJ8a9nWPp2BeEoAYYhHSo2xRmPWART17vTpSxt2q6iNp7BOozW557qqa95eNjUO4gKs0CyvJbYGGku1tA",
+			algorithm: "pbkdf2-sha512",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, password := range []string{tt.password, tt.password + "!"} {
+				result, err := runPasswordVerify(map[string]interface{}{"password": password, "hash": tt.hash})
+
+				var got PasswordVerification
+				resultJSON(t, result, err, &got)
+				if want := password == tt.password; got.Valid != want || got.Algorithm != tt.algorithm {
+					t.Errorf("password_verify(%q) = %+v, want valid %t with %s", password, got, want, tt.algorithm)
+				}
+			}
+		})
+	}
+}
+
+func TestPasswordVerifyArgon2idRoundTrip(t *testing.T) {
+	result, err := runArgon2id(map[string]interface{}{"password": "correct horse", "memory": float64(64)})
+	hash := resultText(t, result, err)
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=2,p=1$") {
+		t.Fatalf("argon2id() = %s, want a PHC string with m=64,t=2,p=1", hash)
+	}
+
+	result, err = runPasswordVerify(map[string]interface{}{"password": "correct horse", "hash": hash})
+	var got PasswordVerification
+	resultJSON(t, result, err, &got)
+	if !got.Valid {
+		t.Errorf("password_verify() = %+v, want valid", got)
+	}
+}
+
+func TestPasswordVerifyRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr string
+	}{
+		{name: "unknown format", hash: "5f4dcc3b5aa765d61d8327deb882cf99", wantErr: "unrecognized hash format"},
+		{name: "bcrypt cost above 16", hash: "$2a$17$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", wantErr: "cost 17 is above the maximum of 16"},
+		{name: "bcrypt cost 31", hash: "$2b$31$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", wantErr: "cost 31 is above the maximum of 16"},
+		{name: "missing sections", hash: "$scrypt$ln=10,r=8,p=1", wantErr: "invalid PHC string"},
+		{name: "empty hash", hash: "$scrypt$ln=10,r=8,p=1$c2FsdHNhbHQ$", wantErr: "hash"},
+		{name: "short hash", hash: "$pbkdf2-sha256$i=1000$c2FsdHNhbHQ$AAAA", wantErr: "hash"},
+		{name: "short salt", hash: "$scrypt$ln=10,r=8,p=1$TmFDbA$AOLXEESCcPmf2DxU3D47ZJxp5ZTcHC0S2Mb2eFXc4tI", wantErr: "salt"},
+		{name: "missing parameter", hash: "$scrypt$ln=10,r=8$c2FsdHNhbHQ$AOLXEESCcPmf2DxU3D47ZJxp5ZTcHC0S2Mb2eFXc4tI", wantErr: "missing p parameter"},
+		{name: "argon2 memory too large", hash: "$argon2id$v=19$m=2097152,t=2,p=1$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", wantErr: "invalid m parameter: must be between 8 and 1048576"},
+		{name: "argon2 memory below 8 per lane", hash: "$argon2id$v=19$m=16,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", wantErr: "at least 8 KiB per unit of parallelism"},
+		{name: "argon2 too many iterations", hash: "$argon2id$v=19$m=64,t=101,p=1$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", wantErr: "invalid t parameter"},
+		{name: "argon2 unknown version", hash: "$argon2id$v=16$m=64,t=2,p=1$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", wantErr: "unsupported argon2 version 16"},
+		{name: "scrypt ln too large", hash: "$scrypt$ln=21,r=8,p=1$c2FsdHNhbHQ$AOLXEESCcPmf2DxU3D47ZJxp5ZTcHC0S2Mb2eFXc4tI", wantErr: "invalid ln parameter"},
+		{name: "scrypt over 1 GiB", hash: "$scrypt$ln=20,r=16,p=1$c2FsdHNhbHQ$AOLXEESCcPmf2DxU3D47ZJxp5ZTcHC0S2Mb2eFXc4tI", wantErr: "more than 1 GiB"},
+		{name: "pbkdf2 too few iterations", hash: "$pbkdf2-sha256$i=999$c2FsdHNhbHQ$E196ZhRPzw+wA84EjzHwJO1cv/MFJdO6C/sxmUeTYqY", wantErr: "invalid i parameter"},
+		{name: "pbkdf2 unknown hash", hash: "$pbkdf2-md5$i=1000$c2FsdHNhbHQ$E196ZhRPzw+wA84EjzHwJO1cv/MFJdO6C/sxmUeTYqY", wantErr: "unsupported algorithm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runPasswordVerify(map[string]interface{}{"password": "password", "hash": tt.hash})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("password_verify() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
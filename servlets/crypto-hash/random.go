@@ -0,0 +1,496 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/sethvargo/go-diceware/diceware"
+)
+
+var countProperty = map[string]interface{}{
+	"type":        "integer",
+	"description": "how many values to generate (default: 1)",
+	"minimum":     1,
+	"maximum":     100,
+	"default":     1,
+}
+
+var (
+	RandomUUIDTool = ToolDescription{
+		Name:        "random_uuid",
+		Description: "Generate random RFC 9562 UUIDs. Version 4 is fully random, version 7 is prefixed with a millisecond timestamp so values sort by creation time.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"version": map[string]interface{}{
+					"type":        "integer",
+					"description": "the UUID version (default: 4)",
+					"enum":        []int{4, 7},
+					"default":     4,
+				},
+				"count": countProperty,
+			},
+		},
+	}
+	RandomULIDTool = ToolDescription{
+		Name:        "random_ulid",
+		Description: "Generate ULIDs: 26 character, lexicographically sortable identifiers made of a millisecond timestamp and 80 random bits.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"count": countProperty,
+			},
+		},
+	}
+	RandomNanoIDTool = ToolDescription{
+		Name:        "random_nanoid",
+		Description: "Generate NanoIDs: compact, URL-safe random identifiers.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"size": map[string]interface{}{
+					"type":        "integer",
+					"description": "number of characters (default: 21)",
+					"minimum":     2,
+					"maximum":     256,
+					"default":     21,
+				},
+				"alphabet": map[string]interface{}{
+					"type":        "string",
+					"description": "characters to draw from (default: A-Za-z0-9_-)",
+				},
+				"count": countProperty,
+			},
+		},
+	}
+	RandomBytesTool = ToolDescription{
+		Name:        "random_bytes",
+		Description: "Generate cryptographically secure random bytes, e.g. for API tokens, secrets or keys.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"length": map[string]interface{}{
+					"type":        "integer",
+					"description": "number of bytes (default: 32)",
+					"minimum":     1,
+					"maximum":     4096,
+					"default":     32,
+				},
+				"output_encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "how to encode the bytes (default: hex)",
+					"enum":        []string{"hex", "base64", "base64url"},
+					"default":     "hex",
+				},
+				"count": countProperty,
+			},
+		},
+	}
+	RandomPasswordTool = ToolDescription{
+		Name:        "random_password",
+		Description: "Generate random passwords from configurable character classes. Every enabled class is guaranteed to appear at least once.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"length": map[string]interface{}{
+					"type":        "integer",
+					"description": "number of characters (default: 20)",
+					"minimum":     4,
+					"maximum":     1024,
+					"default":     20,
+				},
+				"lowercase": map[string]interface{}{
+					"type":        "boolean",
+					"description": "include a-z (default: true)",
+					"default":     true,
+				},
+				"uppercase": map[string]interface{}{
+					"type":        "boolean",
+					"description": "include A-Z (default: true)",
+					"default":     true,
+				},
+				"digits": map[string]interface{}{
+					"type":        "boolean",
+					"description": "include 0-9 (default: true)",
+					"default":     true,
+				},
+				"symbols": map[string]interface{}{
+					"type":        "boolean",
+					"description": "include punctuation (default: true)",
+					"default":     true,
+				},
+				"symbol_set": map[string]interface{}{
+					"type":        "string",
+					"description": "the punctuation characters to use when symbols are enabled (default: !@#$%^&*()-_=+[]{};:,.?/~)",
+				},
+				"exclude_ambiguous": map[string]interface{}{
+					"type":        "boolean",
+					"description": "leave out characters that are easily confused, such as 0/O and 1/l/I (default: false)",
+					"default":     false,
+				},
+				"count": countProperty,
+			},
+		},
+	}
+	RandomPassphraseTool = ToolDescription{
+		Name:        "random_passphrase",
+		Description: "Generate diceware passphrases from the EFF word lists.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"words": map[string]interface{}{
+					"type":        "integer",
+					"description": "number of words (default: 6)",
+					"minimum":     3,
+					"maximum":     32,
+					"default":     6,
+				},
+				"wordlist": map[string]interface{}{
+					"type":        "string",
+					"description": "eff-large has 7776 words, eff-short 1296 shorter words (default: eff-large)",
+					"enum":        []string{"eff-large", "eff-short"},
+					"default":     "eff-large",
+				},
+				"separator": map[string]interface{}{
+					"type":        "string",
+					"description": "placed between words (default: -)",
+					"default":     "-",
+				},
+				"capitalize": map[string]interface{}{
+					"type":        "boolean",
+					"description": "capitalize the first letter of each word (default: false)",
+					"default":     false,
+				},
+				"count": countProperty,
+			},
+		},
+	}
+)
+
+// RandomValues is returned by the random_* tools. EntropyBits estimates
+// the randomness of each value, assuming the generator's parameters are known
+// to an attacker.
+type RandomValues struct {
+	Values      []string `json:"values"`
+	EntropyBits float64  `json:"entropy_bits"`
+}
+
+const (
+	nanoIDAlphabet  = "useandom-26T198340PX75pxJACKVERYMINDBUSHWOLF_GQZbfghjklqvwyzrict"
+	crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	passwordLower   = "abcdefghijklmnopqrstuvwxyz"
+	passwordUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordDigits  = "0123456789"
+	passwordSymbols = "!@#$%^&*()-_=+[]{};:,.?/~"
+	ambiguousChars  = "0O1lI|`'\""
+)
+
+// randomIndex returns a uniformly distributed integer in [0, n).
+func randomIndex(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
+
+// randomString draws size characters uniformly from alphabet.
+func randomString(alphabet []rune, size int) (string, error) {
+	out := make([]rune, size)
+	for i := range out {
+		j, err := randomIndex(len(alphabet))
+		if err != nil {
+			return "", err
+		}
+		out[i] = alphabet[j]
+	}
+	return string(out), nil
+}
+
+// generateValues calls gen count times, where count comes from the count
+// argument.
+func generateValues(args map[string]interface{}, gen func() (string, error)) ([]string, error) {
+	count, err := intArg(args, "count", 1, 1, 100)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, count)
+	for i := range values {
+		if values[i], err = gen(); err != nil {
+			return nil, fmt.Errorf("failed to generate random value: %w", err)
+		}
+	}
+	return values, nil
+}
+
+// newUUID returns a version 4 or 7 UUID in its canonical string form.
+func newUUID(version int) (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+	if version == 7 {
+		var ts [8]byte
+		binary.BigEndian.PutUint64(ts[:], uint64(time.Now().UnixMilli()))
+		copy(u[:6], ts[2:])
+	}
+	u[6] = u[6]&0x0f | byte(version)<<4
+	u[8] = u[8]&0x3f | 0x80
+
+	h := hex.EncodeToString(u[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
+
+// newULID returns a ULID: a 48 bit millisecond timestamp followed by 80
+// random bits, in Crockford base32.
+func newULID() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[6:]); err != nil {
+		return "", err
+	}
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(time.Now().UnixMilli()))
+	copy(u[:6], ts[2:])
+
+	// 128 bits encode to 26 characters, the first carrying only 3 bits
+	hi := binary.BigEndian.Uint64(u[:8])
+	lo := binary.BigEndian.Uint64(u[8:])
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = crockfordBase32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out), nil
+}
+
+func runRandomUUID(args map[string]interface{}) (CallToolResult, error) {
+	version, err := intArg(args, "version", 4, 4, 7)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if version != 4 && version != 7 {
+		return CallToolResult{}, errors.New("version must be 4 or 7")
+	}
+
+	values, err := generateValues(args, func() (string, error) { return newUUID(version) })
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	entropy := 122.0
+	if version == 7 {
+		entropy = 74
+	}
+	return jsonResult(RandomValues{Values: values, EntropyBits: entropy})
+}
+
+func runRandomULID(args map[string]interface{}) (CallToolResult, error) {
+	values, err := generateValues(args, newULID)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	return jsonResult(RandomValues{Values: values, EntropyBits: 80})
+}
+
+func runRandomNanoID(args map[string]interface{}) (CallToolResult, error) {
+	size, err := intArg(args, "size", 21, 2, 256)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	alphabet := []rune(nanoIDAlphabet)
+	if alphabetVal, exists := args["alphabet"].(string); exists && alphabetVal != "" {
+		alphabet = uniqueRunes(alphabetVal)
+		if len(alphabet) < 2 {
+			return CallToolResult{}, errors.New("alphabet must contain at least 2 distinct characters")
+		}
+	}
+
+	values, err := generateValues(args, func() (string, error) { return randomString(alphabet, size) })
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	return jsonResult(RandomValues{Values: values, EntropyBits: entropyBits(len(alphabet), size)})
+}
+
+func runRandomBytes(args map[string]interface{}) (CallToolResult, error) {
+	length, err := intArg(args, "length", 32, 1, 4096)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	encoding := "hex"
+	if encodingVal, exists := args["output_encoding"].(string); exists && encodingVal != "" {
+		encoding = encodingVal
+	}
+	if encoding != "hex" && encoding != "base64" && encoding != "base64url" {
+		return CallToolResult{}, fmt.Errorf("unsupported output_encoding %q, expected hex, base64 or base64url", encoding)
+	}
+
+	values, err := generateValues(args, func() (string, error) {
+		b := make([]byte, length)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		switch encoding {
+		case "base64":
+			return base64.StdEncoding.EncodeToString(b), nil
+		case "base64url":
+			return base64.RawURLEncoding.EncodeToString(b), nil
+		default:
+			return hex.EncodeToString(b), nil
+		}
+	})
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	return jsonResult(RandomValues{Values: values, EntropyBits: float64(length * 8)})
+}
+
+func runRandomPassword(args map[string]interface{}) (CallToolResult, error) {
+	length, err := intArg(args, "length", 20, 4, 1024)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	symbols := passwordSymbols
+	if symbolsVal, exists := args["symbol_set"].(string); exists && symbolsVal != "" {
+		symbols = symbolsVal
+	}
+	excludeAmbiguous, _ := args["exclude_ambiguous"].(bool)
+
+	var classes [][]rune
+	for _, class := range []struct {
+		name  string
+		chars string
+	}{
+		{"lowercase", passwordLower},
+		{"uppercase", passwordUpper},
+		{"digits", passwordDigits},
+		{"symbols", symbols},
+	} {
+		if enabled, exists := args[class.name].(bool); exists && !enabled {
+			continue
+		}
+		chars := class.chars
+		if excludeAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguousChars, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+		if runes := uniqueRunes(chars); len(runes) > 0 {
+			classes = append(classes, runes)
+		}
+	}
+	if len(classes) == 0 {
+		return CallToolResult{}, errors.New("at least one character class must be enabled")
+	}
+	if length < len(classes) {
+		return CallToolResult{}, fmt.Errorf("length must be at least %d to include every enabled character class", len(classes))
+	}
+
+	var alphabet []rune
+	for _, class := range classes {
+		alphabet = append(alphabet, class...)
+	}
+	alphabet = uniqueRunes(string(alphabet))
+
+	values, err := generateValues(args, func() (string, error) {
+		password := make([]rune, 0, length)
+		for _, class := range classes {
+			s, err := randomString(class, 1)
+			if err != nil {
+				return "", err
+			}
+			password = append(password, []rune(s)...)
+		}
+		rest, err := randomString(alphabet, length-len(classes))
+		if err != nil {
+			return "", err
+		}
+		password = append(password, []rune(rest)...)
+
+		// shuffle so the guaranteed characters are not always first
+		for i := len(password) - 1; i > 0; i-- {
+			j, err := randomIndex(i + 1)
+			if err != nil {
+				return "", err
+			}
+			password[i], password[j] = password[j], password[i]
+		}
+		return string(password), nil
+	})
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	return jsonResult(RandomValues{Values: values, EntropyBits: entropyBits(len(alphabet), length)})
+}
+
+func runRandomPassphrase(args map[string]interface{}) (CallToolResult, error) {
+	words, err := intArg(args, "words", 6, 3, 32)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	wordList := diceware.WordListEffLarge()
+	if wordlistVal, exists := args["wordlist"].(string); exists && wordlistVal != "" {
+		switch wordlistVal {
+		case "eff-large":
+		case "eff-short":
+			wordList = diceware.WordListEffSmall()
+		default:
+			return CallToolResult{}, fmt.Errorf("unsupported wordlist %q, expected eff-large or eff-short", wordlistVal)
+		}
+	}
+	separator := "-"
+	if separatorVal, exists := args["separator"].(string); exists {
+		separator = separatorVal
+	}
+	capitalize, _ := args["capitalize"].(bool)
+
+	values, err := generateValues(args, func() (string, error) {
+		list, err := diceware.GenerateWithWordList(words, wordList)
+		if err != nil {
+			return "", err
+		}
+		if capitalize {
+			for i, w := range list {
+				list[i] = strings.ToUpper(w[:1]) + w[1:]
+			}
+		}
+		return strings.Join(list, separator), nil
+	})
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	// each word is chosen by rolling Digits() six-sided dice
+	listSize := int(math.Pow(6, float64(wordList.Digits())))
+	return jsonResult(RandomValues{Values: values, EntropyBits: entropyBits(listSize, words)})
+}
+
+// entropyBits returns the entropy of n symbols drawn uniformly from an
+// alphabet of the given size, rounded to two decimals.
+func entropyBits(alphabet, n int) float64 {
+	return math.Round(float64(n)*math.Log2(float64(alphabet))*100) / 100
+}
+
+func uniqueRunes(s string) []rune {
+	seen := map[rune]bool{}
+	var runes []rune
+	for _, r := range s {
+		if !seen[r] {
+			seen[r] = true
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+var (
+	SecretSplitTool = ToolDescription{
+		Name:        "secret_split",
+		Description: "Split a secret into shares with Shamir's secret sharing over GF(256), so that any `threshold` shares recover it and fewer reveal nothing. Each share is the secret-sized share data followed by a one byte share index, the same layout HashiCorp Vault uses for unseal keys. Either text or path must be provided.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"shares", "threshold"},
+			"properties": withProperties(inputProperties, map[string]interface{}{
+				"shares": map[string]interface{}{
+					"type":        "integer",
+					"description": "the number of shares to create",
+					"minimum":     2,
+					"maximum":     255,
+				},
+				"threshold": map[string]interface{}{
+					"type":        "integer",
+					"description": "the number of shares needed to recover the secret",
+					"minimum":     2,
+					"maximum":     255,
+				},
+				"share_encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "how to encode the shares (default: hex)",
+					"enum":        []string{"hex", "base64"},
+					"default":     "hex",
+				},
+			}),
+		},
+	}
+	SecretCombineTool = ToolDescription{
+		Name:        "secret_combine",
+		Description: "Recover a secret from shares created by `secret_split`. Shares carry no checksum, so combining too few or mismatched shares yields a wrong secret rather than an error.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"shares"},
+			"properties": map[string]interface{}{
+				"shares": map[string]interface{}{
+					"type":        "array",
+					"description": "at least `threshold` distinct shares, in any order",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"share_encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "how the shares are encoded (default: hex)",
+					"enum":        []string{"hex", "base64"},
+					"default":     "hex",
+				},
+				"output_encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "encoding of the recovered secret. Use hex or base64 for binary secrets (default: utf8)",
+					"enum":        []string{"utf8", "hex", "base64"},
+					"default":     "utf8",
+				},
+			},
+		},
+	}
+)
+
+// SecretShares is returned by secret_split.
+type SecretShares struct {
+	Threshold int           `json:"threshold"`
+	Shares    []SecretShare `json:"shares"`
+}
+
+// SecretShare is one encoded share and its index (x coordinate).
+type SecretShare struct {
+	Index int    `json:"index"`
+	Share string `json:"share"`
+}
+
+// GF(256) with the AES reduction polynomial x^8 + x^4 + x^3 + x + 1, using
+// log and exp tables over the generator 3.
+var gfExp, gfLog = gfTables()
+
+func gfTables() (exp [510]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		exp[i+255] = x
+		log[x] = byte(i)
+		// multiply by 3: x*2 ^ x, reduced
+		x2 := x << 1
+		if x&0x80 != 0 {
+			x2 ^= 0x1b
+		}
+		x = x2 ^ x
+	}
+	return exp, log
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// shamirSplit returns n shares of secret, any k of which recover it. Each
+// share is len(secret) bytes of y values followed by its x coordinate.
+func shamirSplit(secret []byte, n, k int) ([][]byte, error) {
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = byte(i + 1)
+	}
+
+	coefficients := make([]byte, k-1)
+	for pos, s := range secret {
+		if _, err := rand.Read(coefficients); err != nil {
+			return nil, fmt.Errorf("failed to generate coefficients: %w", err)
+		}
+		for _, share := range shares {
+			x := share[len(secret)]
+			// Horner's method, the constant term is the secret byte
+			y := byte(0)
+			for j := len(coefficients) - 1; j >= 0; j-- {
+				y = gfMul(y, x) ^ coefficients[j]
+			}
+			share[pos] = gfMul(y, x) ^ s
+		}
+	}
+	return shares, nil
+}
+
+// shamirCombine interpolates the shares' polynomials at x = 0.
+func shamirCombine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errors.New("at least 2 shares are required")
+	}
+	size := len(shares[0])
+	if size < 2 {
+		return nil, errors.New("shares are too short")
+	}
+	xs := make([]byte, len(shares))
+	seen := map[byte]bool{}
+	for i, share := range shares {
+		if len(share) != size {
+			return nil, errors.New("all shares must be the same length")
+		}
+		xs[i] = share[size-1]
+		if xs[i] == 0 {
+			return nil, fmt.Errorf("share %d has an invalid index of 0", i)
+		}
+		if seen[xs[i]] {
+			return nil, fmt.Errorf("duplicate share index %d", xs[i])
+		}
+		seen[xs[i]] = true
+	}
+
+	secret := make([]byte, size-1)
+	for i, share := range shares {
+		// Lagrange basis polynomial for share i evaluated at 0
+		basis := byte(1)
+		for j := range shares {
+			if i != j {
+				basis = gfMul(basis, gfDiv(xs[j], xs[i]^xs[j]))
+			}
+		}
+		for pos := range secret {
+			secret[pos] ^= gfMul(share[pos], basis)
+		}
+	}
+	return secret, nil
+}
+
+func runSecretSplit(args map[string]interface{}) (CallToolResult, error) {
+	n, err := intArg(args, "shares", 0, 2, 255)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	k, err := intArg(args, "threshold", 0, 2, 255)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if n == 0 || k == 0 {
+		return CallToolResult{}, errors.New("shares and threshold must be provided")
+	}
+	if k > n {
+		return CallToolResult{}, errors.New("threshold must not be greater than shares")
+	}
+	encoding := "hex"
+	if encodingVal, exists := args["share_encoding"].(string); exists && encodingVal != "" {
+		encoding = encodingVal
+	}
+	if encoding != "hex" && encoding != "base64" {
+		return CallToolResult{}, fmt.Errorf("unsupported share_encoding %q, expected hex or base64", encoding)
+	}
+
+	secret, err := readInput(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	if len(secret) == 0 {
+		return CallToolResult{}, errors.New("secret must not be empty")
+	}
+
+	shares, err := shamirSplit(secret, n, k)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	result := SecretShares{Threshold: k}
+	for _, share := range shares {
+		encoded := hex.EncodeToString(share)
+		if encoding == "base64" {
+			encoded = base64.StdEncoding.EncodeToString(share)
+		}
+		result.Shares = append(result.Shares, SecretShare{Index: int(share[len(share)-1]), Share: encoded})
+	}
+	return jsonResult(result)
+}
+
+func runSecretCombine(args map[string]interface{}) (CallToolResult, error) {
+	list, ok := args["shares"].([]interface{})
+	if !ok {
+		return CallToolResult{}, errors.New("shares must be provided")
+	}
+	encoding := "hex"
+	if encodingVal, exists := args["share_encoding"].(string); exists && encodingVal != "" {
+		encoding = encodingVal
+	}
+	if encoding != "hex" && encoding != "base64" {
+		return CallToolResult{}, fmt.Errorf("unsupported share_encoding %q, expected hex or base64", encoding)
+	}
+
+	shares := make([][]byte, len(list))
+	for i, item := range list {
+		s, _ := item.(string)
+		share, err := decodeInput(s, encoding)
+		if err != nil {
+			return CallToolResult{}, fmt.Errorf("shares[%d]: %w", i, err)
+		}
+		shares[i] = share
+	}
+
+	secret, err := shamirCombine(shares)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	outputEncoding, _ := args["output_encoding"].(string)
+	switch outputEncoding {
+	case "", "utf8":
+		if !utf8.Valid(secret) {
+			return CallToolResult{}, errors.New("recovered secret is not valid UTF-8, use output_encoding hex or base64, or check the shares")
+		}
+		return textResult(string(secret)), nil
+	case "hex":
+		return textResult(hex.EncodeToString(secret)), nil
+	case "base64":
+		return textResult(base64.StdEncoding.EncodeToString(secret)), nil
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported output_encoding %q, expected utf8, hex or base64", outputEncoding)
+	}
+}
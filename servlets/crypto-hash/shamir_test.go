@@ -0,0 +1,99 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestGFMul(t *testing.T) {
+	// FIPS 197 section 4.2
+	tests := []struct{ a, b, want byte }{
+		{0x57, 0x83, 0xc1},
+		{0x57, 0x13, 0xfe},
+		{0x57, 0x01, 0x57},
+		{0x57, 0x00, 0x00},
+	}
+	for _, tt := range tests {
+		if got := gfMul(tt.a, tt.b); got != tt.want {
+			t.Errorf("gfMul(%#x, %#x) = %#x, want %#x", tt.a, tt.b, got, tt.want)
+		}
+		if tt.b != 0 {
+			if got := gfDiv(tt.want, tt.b); got != tt.a {
+				t.Errorf("gfDiv(%#x, %#x) = %#x, want %#x", tt.want, tt.b, got, tt.a)
+			}
+		}
+	}
+}
+
+// vaultShares split "vault" with threshold 3, using the polynomials
+// s + 0x11x + 0x22x², s + 0x33x + 0x44x² and so on for each byte.
+var vaultShares = []interface{}{"451646934701", "dc0c5c94c302", "ef7b6f6bf003", "248100f36804", "17f6330c5b05"}
+
+func TestSecretCombineKnownShares(t *testing.T) {
+	for _, shares := range [][]interface{}{
+		vaultShares[:3],
+		vaultShares[2:],
+		{vaultShares[4], vaultShares[0], vaultShares[2]},
+		vaultShares,
+	} {
+		result, err := runSecretCombine(map[string]interface{}{"shares": shares})
+		if got := resultText(t, result, err); got != "vault" {
+			t.Errorf("secret_combine(%v) = %q, want vault", shares, got)
+		}
+	}
+
+	result, err := runSecretCombine(map[string]interface{}{"shares": vaultShares[:2], "output_encoding": "hex"})
+	if got := resultText(t, result, err); got == "7661756c74" {
+		t.Error("secret_combine() recovered the secret from fewer shares than the threshold")
+	}
+}
+
+func TestSecretSplitCombine(t *testing.T) {
+	result, err := runSecretSplit(map[string]interface{}{
+		"text":           "00ff10e0",
+		"encoding":       "hex",
+		"shares":         float64(5),
+		"threshold":      float64(3),
+		"share_encoding": "base64",
+	})
+	var split SecretShares
+	resultJSON(t, result, err, &split)
+	if split.Threshold != 3 || len(split.Shares) != 5 {
+		t.Fatalf("secret_split() = %+v, want 5 shares with threshold 3", split)
+	}
+
+	// every combination of three shares recovers the secret
+	for i := 0; i < 5; i++ {
+		for j := i + 1; j < 5; j++ {
+			for k := j + 1; k < 5; k++ {
+				shares := []interface{}{split.Shares[i].Share, split.Shares[j].Share, split.Shares[k].Share}
+				result, err := runSecretCombine(map[string]interface{}{
+					"shares":          shares,
+					"share_encoding":  "base64",
+					"output_encoding": "hex",
+				})
+				if got := resultText(t, result, err); got != "00ff10e0" {
+					t.Errorf("secret_combine() of shares %d, %d and %d = %s, want 00ff10e0", i+1, j+1, k+1, got)
+				}
+			}
+		}
+	}
+}
+
+func TestSecretCombineRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		shares []interface{}
+	}{
+		{name: "single share", shares: vaultShares[:1]},
+		{name: "duplicate index", shares: []interface{}{vaultShares[0], vaultShares[0]}},
+		{name: "index 0", shares: []interface{}{"451646934700", vaultShares[1]}},
+		{name: "different lengths", shares: []interface{}{vaultShares[0], "dc0c02"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runSecretCombine(map[string]interface{}{"shares": tt.shares}); err == nil {
+				t.Errorf("secret_combine(%v) succeeded, want an error", tt.shares)
+			}
+		})
+	}
+}
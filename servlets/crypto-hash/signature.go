@@ -0,0 +1,427 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var signatureProperties = map[string]interface{}{
+	"hash": map[string]interface{}{
+		"type":        "string",
+		"description": "digest used for RSA and ECDSA, ignored for Ed25519 (default: sha256)",
+		"enum":        []string{"sha256", "sha384", "sha512"},
+		"default":     "sha256",
+	},
+	"padding": map[string]interface{}{
+		"type":        "string",
+		"description": "RSA signature scheme (default: pkcs1v15)",
+		"enum":        []string{"pkcs1v15", "pss"},
+		"default":     "pkcs1v15",
+	},
+	"signature_format": map[string]interface{}{
+		"type":        "string",
+		"description": "ECDSA signature layout: der (ASN.1, as produced by OpenSSL) or raw (r || s, as used by JWS/WebCrypto) (default: der)",
+		"enum":        []string{"der", "raw"},
+		"default":     "der",
+	},
+	"signature_encoding": map[string]interface{}{
+		"type":        "string",
+		"description": "encoding of the signature (default: base64)",
+		"enum":        []string{"hex", "base64", "base64url"},
+		"default":     "base64",
+	},
+}
+
+var (
+	KeyGenerateTool = ToolDescription{
+		Name:        "key_generate",
+		Description: "Generate an Ed25519, ECDSA P-256 or RSA-2048/4096 keypair as PEM (PKCS#8 and PKIX), JWK or OpenSSH. Keys are generated inside the servlet, so only use them for testing and fixtures unless the conversation is trusted.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"type"},
+			"properties": map[string]interface{}{
+				"type": map[string]interface{}{
+					"type":        "string",
+					"description": "the key type",
+					"enum":        []string{"ed25519", "p256", "rsa2048", "rsa4096"},
+				},
+				"format": map[string]interface{}{
+					"type":        "string",
+					"description": "output format (default: pem)",
+					"enum":        []string{"pem", "jwk", "openssh"},
+					"default":     "pem",
+				},
+				"comment": map[string]interface{}{
+					"type":        "string",
+					"description": "comment stored with OpenSSH keys",
+				},
+			},
+		},
+	}
+	SignTool = ToolDescription{
+		Name:        "signature_sign",
+		Description: "Sign a string or file with an Ed25519, ECDSA or RSA private key given as PEM, OpenSSH or JWK. Either text or path must be provided.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"key"},
+			"properties": withProperties(inputProperties, signatureProperties, map[string]interface{}{
+				"key": map[string]interface{}{
+					"type":        "string",
+					"description": "the private key (PEM, OpenSSH or JWK)",
+				},
+			}),
+		},
+	}
+	SignatureVerifyTool = ToolDescription{
+		Name:        "signature_verify",
+		Description: "Verify an Ed25519, ECDSA or RSA signature over a string or file using a public key given as PEM, certificate, OpenSSH authorized_keys line or JWK. Either text or path must be provided.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"key", "signature"},
+			"properties": withProperties(inputProperties, signatureProperties, map[string]interface{}{
+				"key": map[string]interface{}{
+					"type":        "string",
+					"description": "the public key (PEM, certificate, OpenSSH or JWK). A private key also works",
+				},
+				"signature": map[string]interface{}{
+					"type":        "string",
+					"description": "the signature, encoded according to `signature_encoding`",
+				},
+			}),
+		},
+	}
+)
+
+// GeneratedKey is returned by key_generate.
+type GeneratedKey struct {
+	Type       string      `json:"type"`
+	Format     string      `json:"format"`
+	Kid        string      `json:"kid"`
+	PrivateKey interface{} `json:"private_key"`
+	PublicKey  interface{} `json:"public_key"`
+}
+
+// SignatureVerification is returned by signature_verify.
+type SignatureVerification struct {
+	Valid   bool   `json:"valid"`
+	KeyType string `json:"key_type"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+func runKeyGenerate(args map[string]interface{}) (CallToolResult, error) {
+	keyType, ok := args["type"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("type must be provided")
+	}
+	format := "pem"
+	if formatVal, exists := args["format"].(string); exists && formatVal != "" {
+		format = formatVal
+	}
+	comment, _ := args["comment"].(string)
+
+	var (
+		signer crypto.Signer
+		err    error
+	)
+	switch strings.ToLower(keyType) {
+	case "ed25519":
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	case "p256", "p-256", "ecdsa":
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "rsa2048", "rsa":
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "rsa4096":
+		signer, err = rsa.GenerateKey(rand.Reader, 4096)
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported key type %q, expected ed25519, p256, rsa2048 or rsa4096", keyType)
+	}
+	if err != nil {
+		return CallToolResult{}, fmt.Errorf("key generation failed: %w", err)
+	}
+
+	pubJWK, err := publicJWK(signer.Public())
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	kid, err := pubJWK.Thumbprint()
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	out := GeneratedKey{Type: strings.ToLower(keyType), Format: format, Kid: kid}
+	switch format {
+	case "pem":
+		privDER, err := x509.MarshalPKCS8PrivateKey(signer)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		pubDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		out.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}))
+		out.PublicKey = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	case "jwk":
+		privJWK, err := privateJWK(signer)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		privJWK.Kid = kid
+		pubJWK.Kid = kid
+		out.PrivateKey = privJWK
+		out.PublicKey = pubJWK
+	case "openssh":
+		block, err := ssh.MarshalPrivateKey(signer, comment)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		sshPub, err := ssh.NewPublicKey(signer.Public())
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub)))
+		if comment != "" {
+			authorizedKey += " " + comment
+		}
+		out.PrivateKey = string(pem.EncodeToMemory(block))
+		out.PublicKey = authorizedKey
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported format %q, expected pem, jwk or openssh", format)
+	}
+
+	return jsonResult(out)
+}
+
+type signatureOptions struct {
+	hash     crypto.Hash
+	pss      bool
+	rawECDSA bool
+	encoding string
+}
+
+func signatureOptionsFromArgs(args map[string]interface{}) (signatureOptions, error) {
+	opts := signatureOptions{hash: crypto.SHA256, encoding: "base64"}
+
+	if hashVal, exists := args["hash"].(string); exists && hashVal != "" {
+		switch strings.ToLower(hashVal) {
+		case "sha256":
+			opts.hash = crypto.SHA256
+		case "sha384":
+			opts.hash = crypto.SHA384
+		case "sha512":
+			opts.hash = crypto.SHA512
+		default:
+			return opts, fmt.Errorf("unsupported hash %q, expected sha256, sha384 or sha512", hashVal)
+		}
+	}
+	if paddingVal, exists := args["padding"].(string); exists && paddingVal != "" {
+		switch paddingVal {
+		case "pkcs1v15":
+		case "pss":
+			opts.pss = true
+		default:
+			return opts, fmt.Errorf("unsupported padding %q, expected pkcs1v15 or pss", paddingVal)
+		}
+	}
+	if formatVal, exists := args["signature_format"].(string); exists && formatVal != "" {
+		switch formatVal {
+		case "der":
+		case "raw":
+			opts.rawECDSA = true
+		default:
+			return opts, fmt.Errorf("unsupported signature_format %q, expected der or raw", formatVal)
+		}
+	}
+	if encodingVal, exists := args["signature_encoding"].(string); exists && encodingVal != "" {
+		opts.encoding = encodingVal
+	}
+	return opts, nil
+}
+
+// readInput returns the full tool input. Ed25519 signs the message itself
+// rather than a digest, so the input cannot be streamed into a hash.
+func readInput(args map[string]interface{}) ([]byte, error) {
+	r, err := openInput(args)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	return data, nil
+}
+
+func digestInput(h crypto.Hash, args map[string]interface{}) ([]byte, error) {
+	d := h.New()
+	if err := hashInput(d, args); err != nil {
+		return nil, err
+	}
+	return d.Sum(nil), nil
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+func runSign(args map[string]interface{}) (CallToolResult, error) {
+	key, ok := args["key"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("key must be provided")
+	}
+	opts, err := signatureOptionsFromArgs(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	signer, err := parsePrivateKey(key)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	var signature []byte
+	switch k := signer.(type) {
+	case ed25519.PrivateKey:
+		msg, err := readInput(args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		signature = ed25519.Sign(k, msg)
+	case *rsa.PrivateKey:
+		digest, err := digestInput(opts.hash, args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		if opts.pss {
+			signature, err = rsa.SignPSS(rand.Reader, k, opts.hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, k, opts.hash, digest)
+		}
+		if err != nil {
+			return CallToolResult{}, fmt.Errorf("signing failed: %w", err)
+		}
+	case *ecdsa.PrivateKey:
+		digest, err := digestInput(opts.hash, args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return CallToolResult{}, fmt.Errorf("signing failed: %w", err)
+		}
+		if opts.rawECDSA {
+			size := (k.Curve.Params().BitSize + 7) / 8
+			signature = make([]byte, 2*size)
+			r.FillBytes(signature[:size])
+			s.FillBytes(signature[size:])
+		} else {
+			signature, err = asn1.Marshal(ecdsaSignature{r, s})
+			if err != nil {
+				return CallToolResult{}, err
+			}
+		}
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported private key type %T", signer)
+	}
+
+	encoded, err := encodeDigest(signature, opts.encoding)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	return textResult(encoded), nil
+}
+
+func runSignatureVerify(args map[string]interface{}) (CallToolResult, error) {
+	key, ok := args["key"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("key must be provided")
+	}
+	sig, ok := args["signature"].(string)
+	if !ok {
+		return CallToolResult{}, errors.New("signature must be provided")
+	}
+	opts, err := signatureOptionsFromArgs(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	signature, err := decodeSignature(strings.TrimSpace(sig), opts.encoding)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+	pub, err := parsePublicKey(key)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	result := SignatureVerification{}
+	var verifyErr error
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		result.KeyType = "ed25519"
+		msg, err := readInput(args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		if !ed25519.Verify(k, msg, signature) {
+			verifyErr = errors.New("signature mismatch")
+		}
+	case *rsa.PublicKey:
+		result.KeyType = fmt.Sprintf("rsa%d", k.N.BitLen())
+		digest, err := digestInput(opts.hash, args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		if opts.pss {
+			verifyErr = rsa.VerifyPSS(k, opts.hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		} else {
+			verifyErr = rsa.VerifyPKCS1v15(k, opts.hash, digest, signature)
+		}
+	case *ecdsa.PublicKey:
+		result.KeyType = "ecdsa-" + k.Curve.Params().Name
+		digest, err := digestInput(opts.hash, args)
+		if err != nil {
+			return CallToolResult{}, err
+		}
+		var r, s *big.Int
+		if opts.rawECDSA {
+			size := (k.Curve.Params().BitSize + 7) / 8
+			if len(signature) != 2*size {
+				return CallToolResult{}, fmt.Errorf("raw ECDSA signature must be %d bytes", 2*size)
+			}
+			r = new(big.Int).SetBytes(signature[:size])
+			s = new(big.Int).SetBytes(signature[size:])
+		} else {
+			var parsed ecdsaSignature
+			if _, err := asn1.Unmarshal(signature, &parsed); err != nil {
+				return CallToolResult{}, fmt.Errorf("invalid DER signature (try signature_format raw): %w", err)
+			}
+			r, s = parsed.R, parsed.S
+		}
+		if !ecdsa.Verify(k, digest, r, s) {
+			verifyErr = errors.New("signature mismatch")
+		}
+	default:
+		return CallToolResult{}, fmt.Errorf("unsupported public key type %T", pub)
+	}
+
+	result.Valid = verifyErr == nil
+	if verifyErr != nil {
+		result.Reason = verifyErr.Error()
+	}
+	return jsonResult(result)
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var (
+	SSHKeyInspectTool = ToolDescription{
+		Name:        "ssh_key_inspect",
+		Description: "Inspect OpenSSH keys: a private key (OpenSSH or PEM), a public key, or every line of an authorized_keys or known_hosts file. Reports key type, bit length, comment, options and the SHA256 and MD5 fingerprints as `ssh-keygen -l` prints them. Either text or path must be provided.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": withProperties(inputProperties, map[string]interface{}{
+				"passphrase": map[string]interface{}{
+					"type":        "string",
+					"description": "passphrase of an encrypted private key. Not needed for OpenSSH format keys, whose public half is stored unencrypted",
+				},
+			}),
+		},
+	}
+	SSHKnownHostsTool = ToolDescription{
+		Name:        "ssh_known_hosts_entry",
+		Description: "Build known_hosts lines for a host key, hashed as with `HashKnownHosts yes` or `ssh-keygen -H` by default.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"hosts", "key"},
+			"properties": map[string]interface{}{
+				"hosts": map[string]interface{}{
+					"type":        "array",
+					"description": "host names or addresses, optionally with a port (host:port or [host]:port)",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"key": map[string]interface{}{
+					"type":        "string",
+					"description": "the host public key, e.g. the contents of /etc/ssh/ssh_host_ed25519_key.pub or a line of ssh-keyscan output",
+				},
+				"hash": map[string]interface{}{
+					"type":        "boolean",
+					"description": "hash host names so the file does not reveal them (default: true)",
+					"default":     true,
+				},
+				"salt": map[string]interface{}{
+					"type":        "string",
+					"description": "base64 encoded 20 byte salt for reproducible output. Defaults to a random salt per host",
+				},
+			},
+		},
+	}
+)
+
+// SSHKeyInfo describes one OpenSSH key.
+type SSHKeyInfo struct {
+	Line              int      `json:"line,omitempty"`
+	Type              string   `json:"type"`
+	Bits              int      `json:"bits"`
+	Comment           string   `json:"comment,omitempty"`
+	Options           []string `json:"options,omitempty"`
+	Hosts             []string `json:"hosts,omitempty"`
+	Marker            string   `json:"marker,omitempty"`
+	Private           bool     `json:"private,omitempty"`
+	Encrypted         bool     `json:"encrypted,omitempty"`
+	FingerprintSHA256 string   `json:"fingerprint_sha256"`
+	FingerprintMD5    string   `json:"fingerprint_md5"`
+	// Keygen is the line `ssh-keygen -l` prints for the key.
+	Keygen string `json:"ssh_keygen"`
+}
+
+// sshKeyLabels maps key types to the names ssh-keygen prints in
+// parentheses.
+var sshKeyLabels = map[string]string{
+	ssh.KeyAlgoRSA:        "RSA",
+	ssh.KeyAlgoDSA:        "DSA",
+	ssh.KeyAlgoECDSA256:   "ECDSA",
+	ssh.KeyAlgoECDSA384:   "ECDSA",
+	ssh.KeyAlgoECDSA521:   "ECDSA",
+	ssh.KeyAlgoED25519:    "ED25519",
+	ssh.KeyAlgoSKECDSA256: "ECDSA-SK",
+	ssh.KeyAlgoSKED25519:  "ED25519-SK",
+}
+
+func describeSSHKey(key ssh.PublicKey, comment string) SSHKeyInfo {
+	label := sshKeyLabels[key.Type()]
+	bitsKey := key
+	if cert, ok := key.(*ssh.Certificate); ok {
+		bitsKey = cert.Key
+		label = sshKeyLabels[cert.Key.Type()] + "-CERT"
+	}
+
+	info := SSHKeyInfo{
+		Type:              key.Type(),
+		Comment:           comment,
+		FingerprintSHA256: ssh.FingerprintSHA256(key),
+		FingerprintMD5:    "MD5:" + ssh.FingerprintLegacyMD5(key),
+	}
+	if cryptoKey, ok := bitsKey.(ssh.CryptoPublicKey); ok {
+		info.Bits = describePublicKey(cryptoKey.CryptoPublicKey()).Bits
+	}
+
+	if comment == "" {
+		comment = "no comment"
+	}
+	info.Keygen = fmt.Sprintf("%d %s %s (%s)", info.Bits, info.FingerprintSHA256, comment, label)
+	return info
+}
+
+// parseSSHPrivateKey returns the public half of a PEM or OpenSSH private key.
+// Encrypted OpenSSH keys can be described without the passphrase.
+func parseSSHPrivateKey(data []byte, passphrase string) (ssh.PublicKey, bool, error) {
+	var raw interface{}
+	var err error
+	if passphrase != "" {
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase(data, []byte(passphrase))
+	} else {
+		raw, err = ssh.ParseRawPrivateKey(data)
+	}
+
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if missing.PublicKey == nil {
+			return nil, true, errors.New("private key is encrypted, a passphrase must be provided")
+		}
+		return missing.PublicKey, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	// ssh returns Ed25519 keys by pointer
+	if k, ok := raw.(*ed25519.PrivateKey); ok {
+		raw = *k
+	}
+	signer, err := ssh.NewSignerFromKey(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return signer.PublicKey(), passphrase != "", nil
+}
+
+func runSSHKeyInspect(args map[string]interface{}) (CallToolResult, error) {
+	data, err := readInput(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	if bytes.Contains(data, []byte("PRIVATE KEY-----")) {
+		passphrase, _ := args["passphrase"].(string)
+		key, encrypted, err := parseSSHPrivateKey(data, passphrase)
+		if err != nil {
+			return CallToolResult{}, fmt.Errorf("invalid private key: %w", err)
+		}
+		info := describeSSHKey(key, "")
+		info.Private = true
+		info.Encrypted = encrypted
+		return jsonResult([]SSHKeyInfo{info})
+	}
+
+	var keys []SSHKeyInfo
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		if key, comment, options, _, err := ssh.ParseAuthorizedKey(line); err == nil {
+			info := describeSSHKey(key, comment)
+			info.Line = n
+			info.Options = options
+			keys = append(keys, info)
+			continue
+		}
+		marker, hosts, key, comment, _, err := ssh.ParseKnownHosts(line)
+		if err != nil {
+			return CallToolResult{}, fmt.Errorf("line %d is not an OpenSSH public key, authorized_keys or known_hosts entry", n)
+		}
+		info := describeSSHKey(key, comment)
+		info.Line = n
+		info.Hosts = hosts
+		info.Marker = marker
+		keys = append(keys, info)
+	}
+	if err := scanner.Err(); err != nil {
+		return CallToolResult{}, err
+	}
+	if len(keys) == 0 {
+		return CallToolResult{}, errors.New("no keys found")
+	}
+	return jsonResult(keys)
+}
+
+// hashKnownHost returns the hashed known_hosts form of host:
+// |1|base64(salt)|base64(HMAC-SHA1(salt, host)).
+func hashKnownHost(host string, salt []byte) string {
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(host))
+	return "|1|" + base64.StdEncoding.EncodeToString(salt) + "|" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func runSSHKnownHosts(args map[string]interface{}) (CallToolResult, error) {
+	list, ok := args["hosts"].([]interface{})
+	if !ok || len(list) == 0 {
+		return CallToolResult{}, errors.New("hosts must be provided")
+	}
+	keyArg, ok := args["key"].(string)
+	if !ok || keyArg == "" {
+		return CallToolResult{}, errors.New("key must be provided")
+	}
+
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(keyArg))
+	if err != nil {
+		// ssh-keyscan prints the host before the key
+		if _, _, key, _, _, err = ssh.ParseKnownHosts([]byte(keyArg)); err != nil {
+			return CallToolResult{}, errors.New("key must be an OpenSSH public key")
+		}
+	}
+
+	hosts := make([]string, len(list))
+	for i, item := range list {
+		host, _ := item.(string)
+		if strings.TrimSpace(host) == "" {
+			return CallToolResult{}, fmt.Errorf("hosts[%d] must not be empty", i)
+		}
+		hosts[i] = knownhosts.Normalize(strings.TrimSpace(host))
+	}
+
+	if hash, exists := args["hash"].(bool); exists && !hash {
+		return textResult(knownhosts.Line(hosts, key)), nil
+	}
+
+	var salt []byte
+	if saltVal, exists := args["salt"].(string); exists && saltVal != "" {
+		if salt, err = base64.StdEncoding.DecodeString(saltVal); err != nil || len(salt) != sha1.Size {
+			return CallToolResult{}, fmt.Errorf("salt must be %d base64 encoded bytes", sha1.Size)
+		}
+	}
+
+	// hashed entries hold a single host each
+	keyText := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+	lines := make([]string, len(hosts))
+	for i, host := range hosts {
+		hostSalt := salt
+		if hostSalt == nil {
+			hostSalt = make([]byte, sha1.Size)
+			if _, err := rand.Read(hostSalt); err != nil {
+				return CallToolResult{}, fmt.Errorf("failed to generate salt: %w", err)
+			}
+		}
+		lines[i] = hashKnownHost(host, hostSalt) + " " + keyText
+	}
+	return textResult(strings.Join(lines, "\n")), nil
+}
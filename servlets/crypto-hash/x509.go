@@ -0,0 +1,429 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var X509InspectTool = ToolDescription{
+	Name:        "x509_inspect",
+	Description: "Inspect X.509 certificates, certificate chains and certificate signing requests (CSRs) given as PEM or DER. Returns subject, issuer, SANs, validity with days remaining, key type and size, SHA-1 and SHA-256 fingerprints and extensions, and checks that a chain is in leaf-to-root order with each certificate signed by the next. Either text or path must be provided.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": withProperties(inputProperties, map[string]interface{}{
+			"time": map[string]interface{}{
+				"type":        "string",
+				"description": "RFC 3339 time to evaluate validity at. Defaults to now",
+			},
+		}),
+	},
+}
+
+// X509Report is returned by x509_inspect.
+type X509Report struct {
+	Certificates []CertificateInfo `json:"certificates,omitempty"`
+	Requests     []CSRInfo         `json:"certificate_requests,omitempty"`
+	Chain        *ChainValidation  `json:"chain,omitempty"`
+}
+
+// CertificateInfo describes a single certificate.
+type CertificateInfo struct {
+	Subject            string            `json:"subject"`
+	Issuer             string            `json:"issuer"`
+	SerialNumber       string            `json:"serial_number"`
+	Version            int               `json:"version"`
+	SignatureAlgorithm string            `json:"signature_algorithm"`
+	NotBefore          string            `json:"not_before"`
+	NotAfter           string            `json:"not_after"`
+	DaysRemaining      int               `json:"days_remaining"`
+	Status             string            `json:"status"`
+	SANs               *SubjectAltNames  `json:"subject_alt_names,omitempty"`
+	PublicKey          PublicKeyInfo     `json:"public_key"`
+	Fingerprints       map[string]string `json:"fingerprints"`
+	IsCA               bool              `json:"is_ca"`
+	MaxPathLen         *int              `json:"max_path_len,omitempty"`
+	SelfSigned         bool              `json:"self_signed"`
+	KeyUsage           []string          `json:"key_usage,omitempty"`
+	ExtKeyUsage        []string          `json:"ext_key_usage,omitempty"`
+	SubjectKeyID       string            `json:"subject_key_id,omitempty"`
+	AuthorityKeyID     string            `json:"authority_key_id,omitempty"`
+	OCSPServers        []string          `json:"ocsp_servers,omitempty"`
+	IssuingCertURLs    []string          `json:"issuing_certificate_urls,omitempty"`
+	CRLDistribution    []string          `json:"crl_distribution_points,omitempty"`
+	Extensions         []ExtensionInfo   `json:"extensions"`
+}
+
+// CSRInfo describes a certificate signing request.
+type CSRInfo struct {
+	Subject            string           `json:"subject"`
+	SignatureAlgorithm string           `json:"signature_algorithm"`
+	SignatureValid     bool             `json:"signature_valid"`
+	SANs               *SubjectAltNames `json:"subject_alt_names,omitempty"`
+	PublicKey          PublicKeyInfo    `json:"public_key"`
+	Extensions         []ExtensionInfo  `json:"extensions,omitempty"`
+}
+
+// SubjectAltNames lists the names a certificate or CSR is valid for.
+type SubjectAltNames struct {
+	DNSNames       []string `json:"dns,omitempty"`
+	IPAddresses    []string `json:"ip,omitempty"`
+	EmailAddresses []string `json:"email,omitempty"`
+	URIs           []string `json:"uri,omitempty"`
+}
+
+// PublicKeyInfo summarises a public key's algorithm and strength.
+type PublicKeyInfo struct {
+	Type  string `json:"type"`
+	Bits  int    `json:"bits,omitempty"`
+	Curve string `json:"curve,omitempty"`
+}
+
+// ExtensionInfo names an X.509 extension present on a certificate or CSR.
+type ExtensionInfo struct {
+	OID      string `json:"oid"`
+	Name     string `json:"name,omitempty"`
+	Critical bool   `json:"critical"`
+}
+
+// ChainValidation reports whether certificates are ordered leaf first, with
+// each one issued and signed by the next. Root is set when the last
+// certificate is a self-signed root.
+type ChainValidation struct {
+	Valid bool        `json:"valid"`
+	Links []ChainLink `json:"links"`
+	Root  bool        `json:"ends_in_root"`
+}
+
+// ChainLink is the result of checking certificate Index against the next
+// certificate in the chain.
+type ChainLink struct {
+	Index   int    `json:"index"`
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer"`
+	Valid   bool   `json:"valid"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+var extensionNames = map[string]string{
+	"2.5.29.14":               "subjectKeyIdentifier",
+	"2.5.29.15":               "keyUsage",
+	"2.5.29.17":               "subjectAltName",
+	"2.5.29.18":               "issuerAltName",
+	"2.5.29.19":               "basicConstraints",
+	"2.5.29.30":               "nameConstraints",
+	"2.5.29.31":               "cRLDistributionPoints",
+	"2.5.29.32":               "certificatePolicies",
+	"2.5.29.35":               "authorityKeyIdentifier",
+	"2.5.29.37":               "extKeyUsage",
+	"1.3.6.1.5.5.7.1.1":       "authorityInfoAccess",
+	"1.3.6.1.5.5.7.1.24":      "tlsFeature",
+	"1.3.6.1.4.1.11129.2.4.2": "signedCertificateTimestampList",
+	"1.3.6.1.4.1.11129.2.4.3": "ctPrecertificatePoison",
+}
+
+var keyUsageNames = []struct {
+	usage x509.KeyUsage
+	name  string
+}{
+	{x509.KeyUsageDigitalSignature, "digitalSignature"},
+	{x509.KeyUsageContentCommitment, "contentCommitment"},
+	{x509.KeyUsageKeyEncipherment, "keyEncipherment"},
+	{x509.KeyUsageDataEncipherment, "dataEncipherment"},
+	{x509.KeyUsageKeyAgreement, "keyAgreement"},
+	{x509.KeyUsageCertSign, "keyCertSign"},
+	{x509.KeyUsageCRLSign, "cRLSign"},
+	{x509.KeyUsageEncipherOnly, "encipherOnly"},
+	{x509.KeyUsageDecipherOnly, "decipherOnly"},
+}
+
+var extKeyUsageNames = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageAny:                        "any",
+	x509.ExtKeyUsageServerAuth:                 "serverAuth",
+	x509.ExtKeyUsageClientAuth:                 "clientAuth",
+	x509.ExtKeyUsageCodeSigning:                "codeSigning",
+	x509.ExtKeyUsageEmailProtection:            "emailProtection",
+	x509.ExtKeyUsageIPSECEndSystem:             "ipsecEndSystem",
+	x509.ExtKeyUsageIPSECTunnel:                "ipsecTunnel",
+	x509.ExtKeyUsageIPSECUser:                  "ipsecUser",
+	x509.ExtKeyUsageTimeStamping:               "timeStamping",
+	x509.ExtKeyUsageOCSPSigning:                "OCSPSigning",
+	x509.ExtKeyUsageMicrosoftServerGatedCrypto: "msSGC",
+	x509.ExtKeyUsageNetscapeServerGatedCrypto:  "nsSGC",
+}
+
+// describePublicKey returns the algorithm, size and curve of pub.
+func describePublicKey(pub crypto.PublicKey) PublicKeyInfo {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return PublicKeyInfo{Type: "RSA", Bits: key.N.BitLen()}
+	case *ecdsa.PublicKey:
+		params := key.Curve.Params()
+		return PublicKeyInfo{Type: "ECDSA", Bits: params.BitSize, Curve: params.Name}
+	case ed25519.PublicKey:
+		return PublicKeyInfo{Type: "Ed25519", Bits: 256}
+	case *dsa.PublicKey:
+		return PublicKeyInfo{Type: "DSA", Bits: key.P.BitLen()}
+	default:
+		return PublicKeyInfo{Type: fmt.Sprintf("%T", pub)}
+	}
+}
+
+// fingerprint returns the digest of der as colon separated upper case hex,
+// the format used by openssl x509 -fingerprint.
+func fingerprint(algorithm string, der []byte) string {
+	h, _ := newDigest(algorithm, 0)
+	h.Write(der)
+	return colonHex(h.Sum(nil))
+}
+
+func colonHex(b []byte) string {
+	parts := make([]string, len(b))
+	for i, c := range b {
+		parts[i] = fmt.Sprintf("%02X", c)
+	}
+	return strings.Join(parts, ":")
+}
+
+func describeSANs(dns, emails []string, ips []fmt.Stringer, uris []fmt.Stringer) *SubjectAltNames {
+	if len(dns)+len(emails)+len(ips)+len(uris) == 0 {
+		return nil
+	}
+	sans := &SubjectAltNames{DNSNames: dns, EmailAddresses: emails}
+	for _, ip := range ips {
+		sans.IPAddresses = append(sans.IPAddresses, ip.String())
+	}
+	for _, uri := range uris {
+		sans.URIs = append(sans.URIs, uri.String())
+	}
+	return sans
+}
+
+func describeExtensions(extensions []pkix.Extension) []ExtensionInfo {
+	infos := make([]ExtensionInfo, 0, len(extensions))
+	for _, ext := range extensions {
+		oid := ext.Id.String()
+		infos = append(infos, ExtensionInfo{OID: oid, Name: extensionNames[oid], Critical: ext.Critical})
+	}
+	return infos
+}
+
+func describeCertificate(cert *x509.Certificate, now time.Time) CertificateInfo {
+	info := CertificateInfo{
+		Subject:            cert.Subject.String(),
+		Issuer:             cert.Issuer.String(),
+		SerialNumber:       colonHex(cert.SerialNumber.Bytes()),
+		Version:            cert.Version,
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		NotBefore:          cert.NotBefore.UTC().Format(time.RFC3339),
+		NotAfter:           cert.NotAfter.UTC().Format(time.RFC3339),
+		DaysRemaining:      int(math.Floor(cert.NotAfter.Sub(now).Hours() / 24)),
+		PublicKey:          describePublicKey(cert.PublicKey),
+		Fingerprints: map[string]string{
+			"sha1":   fingerprint("sha1", cert.Raw),
+			"sha256": fingerprint("sha256", cert.Raw),
+		},
+		IsCA:            cert.IsCA,
+		OCSPServers:     cert.OCSPServer,
+		IssuingCertURLs: cert.IssuingCertificateURL,
+		CRLDistribution: cert.CRLDistributionPoints,
+		Extensions:      describeExtensions(cert.Extensions),
+	}
+
+	switch {
+	case now.Before(cert.NotBefore):
+		info.Status = "not_yet_valid"
+	case now.After(cert.NotAfter):
+		info.Status = "expired"
+	default:
+		info.Status = "valid"
+	}
+
+	ips := make([]fmt.Stringer, len(cert.IPAddresses))
+	for i, ip := range cert.IPAddresses {
+		ips[i] = ip
+	}
+	uris := make([]fmt.Stringer, len(cert.URIs))
+	for i, uri := range cert.URIs {
+		uris[i] = uri
+	}
+	info.SANs = describeSANs(cert.DNSNames, cert.EmailAddresses, ips, uris)
+
+	if cert.BasicConstraintsValid && cert.IsCA && (cert.MaxPathLen > 0 || cert.MaxPathLenZero) {
+		maxPathLen := cert.MaxPathLen
+		info.MaxPathLen = &maxPathLen
+	}
+	if bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(cert) == nil {
+		info.SelfSigned = true
+	}
+	for _, ku := range keyUsageNames {
+		if cert.KeyUsage&ku.usage != 0 {
+			info.KeyUsage = append(info.KeyUsage, ku.name)
+		}
+	}
+	for _, eku := range cert.ExtKeyUsage {
+		name, ok := extKeyUsageNames[eku]
+		if !ok {
+			name = fmt.Sprintf("unknown(%d)", eku)
+		}
+		info.ExtKeyUsage = append(info.ExtKeyUsage, name)
+	}
+	for _, oid := range cert.UnknownExtKeyUsage {
+		info.ExtKeyUsage = append(info.ExtKeyUsage, oid.String())
+	}
+	if len(cert.SubjectKeyId) > 0 {
+		info.SubjectKeyID = hex.EncodeToString(cert.SubjectKeyId)
+	}
+	if len(cert.AuthorityKeyId) > 0 {
+		info.AuthorityKeyID = hex.EncodeToString(cert.AuthorityKeyId)
+	}
+	return info
+}
+
+func describeCSR(csr *x509.CertificateRequest) CSRInfo {
+	ips := make([]fmt.Stringer, len(csr.IPAddresses))
+	for i, ip := range csr.IPAddresses {
+		ips[i] = ip
+	}
+	uris := make([]fmt.Stringer, len(csr.URIs))
+	for i, uri := range csr.URIs {
+		uris[i] = uri
+	}
+	return CSRInfo{
+		Subject:            csr.Subject.String(),
+		SignatureAlgorithm: csr.SignatureAlgorithm.String(),
+		SignatureValid:     csr.CheckSignature() == nil,
+		SANs:               describeSANs(csr.DNSNames, csr.EmailAddresses, ips, uris),
+		PublicKey:          describePublicKey(csr.PublicKey),
+		Extensions:         describeExtensions(csr.Extensions),
+	}
+}
+
+// validateChain checks that each certificate was issued and signed by the
+// one that follows it.
+func validateChain(certs []*x509.Certificate) *ChainValidation {
+	chain := &ChainValidation{Valid: true}
+	for i, cert := range certs[:len(certs)-1] {
+		next := certs[i+1]
+		link := ChainLink{Index: i, Subject: cert.Subject.String(), Issuer: next.Subject.String(), Valid: true}
+		switch {
+		case !bytes.Equal(cert.RawIssuer, next.RawSubject):
+			link.Valid = false
+			link.Reason = fmt.Sprintf("issuer %q does not match the subject of certificate %d", cert.Issuer.String(), i+1)
+		case !next.IsCA:
+			link.Valid = false
+			link.Reason = fmt.Sprintf("certificate %d is not a CA", i+1)
+		default:
+			if err := cert.CheckSignatureFrom(next); err != nil {
+				link.Valid = false
+				link.Reason = err.Error()
+			}
+		}
+		chain.Valid = chain.Valid && link.Valid
+		chain.Links = append(chain.Links, link)
+	}
+	last := certs[len(certs)-1]
+	chain.Root = bytes.Equal(last.RawSubject, last.RawIssuer) && last.CheckSignatureFrom(last) == nil
+	return chain
+}
+
+// parseX509 collects the certificates and CSRs in data, which may hold PEM
+// blocks or a single DER structure, raw or base64 encoded.
+func parseX509(data []byte) ([]*x509.Certificate, []*x509.CertificateRequest, error) {
+	var certs []*x509.Certificate
+	var csrs []*x509.CertificateRequest
+
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		der := data
+		if decoded, err := decodeBase64(strings.Join(strings.Fields(string(data)), "")); err == nil {
+			der = decoded
+		}
+		if parsed, err := x509.ParseCertificates(der); err == nil && len(parsed) > 0 {
+			return parsed, nil, nil
+		}
+		csr, err := x509.ParseCertificateRequest(der)
+		if err != nil {
+			return nil, nil, errors.New("input is not a PEM or DER encoded certificate or CSR")
+		}
+		return nil, []*x509.CertificateRequest{csr}, nil
+	}
+
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "CERTIFICATE", "TRUSTED CERTIFICATE":
+			der := block.Bytes
+			if block.Type == "TRUSTED CERTIFICATE" {
+				// OpenSSL appends its trust settings after the certificate
+				var cert asn1.RawValue
+				if _, err := asn1.Unmarshal(der, &cert); err != nil {
+					return nil, nil, fmt.Errorf("certificate %d: %w", len(certs), err)
+				}
+				der = cert.FullBytes
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, nil, fmt.Errorf("certificate %d: %w", len(certs), err)
+			}
+			certs = append(certs, cert)
+		case "CERTIFICATE REQUEST", "NEW CERTIFICATE REQUEST":
+			csr, err := x509.ParseCertificateRequest(block.Bytes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("certificate request %d: %w", len(csrs), err)
+			}
+			csrs = append(csrs, csr)
+		}
+	}
+	if len(certs)+len(csrs) == 0 {
+		return nil, nil, errors.New("no CERTIFICATE or CERTIFICATE REQUEST PEM blocks found")
+	}
+	return certs, csrs, nil
+}
+
+func runX509Inspect(args map[string]interface{}) (CallToolResult, error) {
+	data, err := readInput(args)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	now := time.Now()
+	if timeVal, exists := args["time"].(string); exists && timeVal != "" {
+		if now, err = time.Parse(time.RFC3339, timeVal); err != nil {
+			return CallToolResult{}, errors.New("time must be RFC 3339")
+		}
+	}
+
+	certs, csrs, err := parseX509(data)
+	if err != nil {
+		return CallToolResult{}, err
+	}
+
+	var report X509Report
+	for _, cert := range certs {
+		report.Certificates = append(report.Certificates, describeCertificate(cert, now))
+	}
+	for _, csr := range csrs {
+		report.Requests = append(report.Requests, describeCSR(csr))
+	}
+	if len(certs) > 1 {
+		report.Chain = validateChain(certs)
+	}
+	return jsonResult(report)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"testing"
+)
+
+// trustedCertificate was written by `openssl x509 -addtrust serverAuth
+// -setalias "Test CA" -trustout`, which appends the trust settings after the
+// certificate.
+const trustedCertificate = `-----BEGIN TRUSTED CERTIFICATE-----
+MIIBgzCCASmgAwIBAgIUbNhJoW8Vovaps7nocwRbCmmDD7AwCgYIKoZIzj0EAwIw
+FzEVMBMGA1UEAwwMdGVzdC5leGFtcGxlMB4XDTI2MTAxNjIwMDU0NFoXDTI2MTAx
+NzIwMDU0NFowFzEVMBMGA1UEAwwMdGVzdC5leGFtcGxlMFkwEwYHKoZIzj0CAQYI
+KoZIzj0DAQcDQgAElTlwCimiYV48qnORNr6p4PN1eJ2C9DrhNXom6d4BO7ECJvTq
+gJXFdAq2jXS7Fm0ath57KZNIbf3NUAPlbsvly6NTMFEwHQYDVR0OBBYEFOpBcgko
+xd6JP5RzVTDV0uvln+psMB8GA1UdIwQYMBaAFOpBcgkoxd6JP5RzVTDV0uvln+ps
+MA8GA1UdEwEB/wQFMAMBAf8wCgYIKoZIzj0EAwIDSAAwRQIhAK8snm5skRiWIGuD
+hwvOWcs84W+R7ez9sKWHXKPEfPl2AiAZuNbP77egEUi7kTdz/u/upsgTdzwbNaqE
+RF88jDOzYzAVMAoGCCsGAQUFBwMBDAdUZXN0IENB
+-----END TRUSTED CERTIFICATE-----`
+
+const trustedCertificateSHA256 = "9ec239a073c6db540f328aab55903c5c9be5d5f5c0109e9afc2c95f01e7eecd4"
+
+func TestParseX509TrustedCertificate(t *testing.T) {
+	certs, _, err := parseX509([]byte(trustedCertificate))
+	if err != nil {
+		t.Fatalf("parseX509() error = %s", err)
+	}
+	if len(certs) != 1 {
+		t.Fatalf("parseX509() = %d certificates, want 1", len(certs))
+	}
+	sum := sha256.Sum256(certs[0].Raw)
+	if hex.EncodeToString(sum[:]) != trustedCertificateSHA256 || certs[0].Subject.CommonName != "test.example" {
+		t.Errorf("certificate = %s with fingerprint %x, want test.example with %s", certs[0].Subject, sum, trustedCertificateSHA256)
+	}
+
+	// the same certificate without the trust settings
+	plain := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certs[0].Raw})
+	again, _, err := parseX509(append(plain, trustedCertificate...))
+	if err != nil {
+		t.Fatalf("parseX509() error = %s", err)
+	}
+	if len(again) != 2 || !again[0].Equal(again[1]) {
+		t.Errorf("parseX509() = %d certificates, want the same one twice", len(again))
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/extism/go-pdk"
+)
+
+// installationTokenVar is the plugin var caching the installation token
+// between calls.
+const installationTokenVar = "github-app-installation-token"
+
+// InstallationToken is an installation access token and its expiry, as
+// returned by POST /app/installations/{installation_id}/access_tokens.
+type InstallationToken struct {
+	Token          string    `json:"token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	InstallationID string    `json:"installation_id"`
+}
+
+// resolveToken returns the token used to authenticate requests: the api-key
+// config if set, otherwise an installation token for the configured
+// GitHub App.
+func resolveToken() (string, error) {
+	if apiKey, ok := pdk.GetConfig("api-key"); ok && apiKey != "" {
+		return apiKey, nil
+	}
+
+	appID, _ := pdk.GetConfig("app-id")
+	privateKey, _ := pdk.GetConfig("private-key")
+	installationID, _ := pdk.GetConfig("installation-id")
+	if appID == "" && privateKey == "" && installationID == "" {
+		return "", errors.New("No api-key configured")
+	}
+	if appID == "" || privateKey == "" || installationID == "" {
+		return "", errors.New("GitHub App authentication requires app-id, private-key and installation-id")
+	}
+	return installationToken(appID, privateKey, installationID)
+}
+
+// installationToken returns the cached installation token, exchanging a new
+// app JWT for one when there is none or it is about to expire.
+func installationToken(appID, privateKey, installationID string) (string, error) {
+	if cached := pdk.GetVar(installationTokenVar); cached != nil {
+		var token InstallationToken
+		if err := json.Unmarshal(cached, &token); err == nil &&
+			token.InstallationID == installationID &&
+			time.Now().Add(time.Minute).Before(token.ExpiresAt) {
+			return token.Token, nil
+		}
+	}
+
+	jwt, err := appJWT(appID, privateKey, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%s/access_tokens", apiBase, installationID)
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Creating installation token: ", url))
+
+	req := newRequest(pdk.MethodPost, url)
+	req.SetHeader("Authorization", fmt.Sprint("Bearer ", jwt))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+	resp := send(req)
+	if resp.Status() != 201 {
+		return "", fmt.Errorf("Failed to create installation token: %d %s", resp.Status(), string(resp.Body()))
+	}
+
+	var token InstallationToken
+	if err := json.Unmarshal(resp.Body(), &token); err != nil {
+		return "", fmt.Errorf("Failed to parse installation token: %w", err)
+	}
+	token.InstallationID = installationID
+
+	res, _ := json.Marshal(token)
+	pdk.SetVar(installationTokenVar, res)
+	return token.Token, nil
+}
+
+// clearInstallationToken drops the cached installation token, so the next
+// call creates a new one.
+func clearInstallationToken() {
+	pdk.RemoveVar(installationTokenVar)
+}
+
+// refreshToken is called by send when req was rejected with 401. If req used
+// an installation token, which may have been revoked or expired early, the
+// cached token is dropped and a new one returned. A later request of the
+// same call still carries the old token, so the cache is only dropped when
+// it holds the rejected token. Requests authenticated with the api-key or
+// the app JWT have nothing to refresh.
+func refreshToken(req *request) (string, bool) {
+	rejected, ok := strings.CutPrefix(req.authorization, "token ")
+	if !ok {
+		return "", false
+	}
+	if cached := pdk.GetVar(installationTokenVar); cached != nil {
+		var token InstallationToken
+		if err := json.Unmarshal(cached, &token); err != nil || token.Token == rejected {
+			clearInstallationToken()
+		}
+	}
+	token, err := resolveToken()
+	if err != nil {
+		pdk.Log(pdk.LogWarn, fmt.Sprint("Failed to refresh the installation token: ", err))
+		return "", false
+	}
+	if token == rejected {
+		return "", false
+	}
+	return token, true
+}
+
+// appJWT signs the RS256 JSON Web Token that authenticates as the GitHub App.
+// It is backdated by a minute to allow for clock drift and expires after
+// nine minutes, below GitHub's ten minute limit.
+func appJWT(appID, privateKey string, now time.Time) (string, error) {
+	key, err := parseRSAPrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": appID,
+	})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("Failed to sign app JWT: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey parses the PEM private key downloaded from the GitHub
+// App settings (PKCS #1), or a PKCS #8 RSA key. Config values sometimes have
+// their newlines escaped, which is undone first.
+func parseRSAPrivateKey(privateKey string) (*rsa.PrivateKey, error) {
+	privateKey = strings.ReplaceAll(privateKey, `\n`, "\n")
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, errors.New("Invalid private-key: no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid private-key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("Invalid private-key: GitHub Apps use RSA keys")
+	}
+	return key, nil
+}
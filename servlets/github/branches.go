@@ -38,6 +38,8 @@ var (
 				"direction": prop("string", "The direction of the sort. Default: desc when sort is created or not specified, otherwise asc"),
 				"per_page":  prop("integer", "The number of results per page (max 100)"),
 				"page":      prop("integer", "The page number of the results to fetch"),
+				"max_items": prop("integer", "Follow pagination and return up to this many results across pages (max 1000), with a cursor to continue from when truncated"),
+				"cursor":    prop("string", "The cursor returned by a previous call, to continue listing where it stopped"),
 				"accept":    prop("string", "Response format: raw (default), text, html, or full. Raw returns body, text returns body_text, html returns body_html, full returns all."),
 			},
 			"required": []string{"owner", "repo"},
@@ -97,8 +99,8 @@ func branchCreate(apiKey, owner, repo, branch string, fromBranch *string) CallTo
 		}
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs", owner, repo)
-	req := pdk.NewHTTPRequest(pdk.MethodPost, url)
+	url := fmt.Sprintf("%s/repos/%s/%s/git/refs", apiBase, owner, repo)
+	req := newRequest(pdk.MethodPost, url)
 	req.SetHeader("Authorization", fmt.Sprintf("token %s", apiKey))
 	req.SetHeader("Content-Type", "application/json")
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
@@ -120,7 +122,7 @@ func branchCreate(apiKey, owner, repo, branch string, fromBranch *string) CallTo
 	}
 
 	req.SetBody([]byte(res))
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 201 {
 		return CallToolResult{
 			IsError: some(true),
@@ -165,7 +167,7 @@ func branchPullRequestSchemaFromArgs(args map[string]interface{}) PullRequestSch
 }
 
 func pullRequestList(apiKey string, owner, repo string, args map[string]interface{}) (CallToolResult, error) {
-	baseURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls", owner, repo)
+	baseURL := fmt.Sprintf("%s/repos/%s/%s/pulls", apiBase, owner, repo)
 	params := make([]string, 0)
 
 	// Handle state parameter
@@ -232,10 +234,6 @@ func pullRequestList(apiKey string, owner, repo string, args map[string]interfac
 	url := fmt.Sprintf("%s?%s", baseURL, strings.Join(params, "&"))
 	pdk.Log(pdk.LogDebug, fmt.Sprint("Listing pull requests: ", url))
 
-	// Make request
-	req := pdk.NewHTTPRequest(pdk.MethodGet, url)
-	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
-
 	// Handle Accept header based on requested format
 	acceptHeader := "application/vnd.github+json" // Default recommended header
 	if format, ok := args["accept"].(string); ok {
@@ -250,10 +248,19 @@ func pullRequestList(apiKey string, owner, repo string, args map[string]interfac
 			acceptHeader = "application/vnd.github.full+json"
 		}
 	}
+
+	if paginated(args) {
+		page, err := fetchPages[json.RawMessage](apiKey, url, acceptHeader, args)
+		return pageResult(page, err, "Failed to list pull requests"), nil
+	}
+
+	// Make request
+	req := newRequest(pdk.MethodGet, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", acceptHeader)
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
 
-	resp := req.Send()
+	resp := send(req)
 
 	// Handle response status codes
 	switch resp.Status() {
@@ -292,8 +299,8 @@ func pullRequestList(apiKey string, owner, repo string, args map[string]interfac
 }
 
 func branchCreatePullRequest(apiKey, owner, repo string, pr PullRequestSchema) CallToolResult {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls", owner, repo)
-	req := pdk.NewHTTPRequest(pdk.MethodPost, url)
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls", apiBase, owner, repo)
+	req := newRequest(pdk.MethodPost, url)
 	req.SetHeader("Authorization", fmt.Sprintf("token %s", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
@@ -311,7 +318,7 @@ func branchCreatePullRequest(apiKey, owner, repo string, pr PullRequestSchema) C
 	}
 
 	req.SetBody([]byte(res))
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 201 {
 		return CallToolResult{
 			IsError: some(true),
@@ -331,13 +338,13 @@ func branchCreatePullRequest(apiKey, owner, repo string, pr PullRequestSchema) C
 }
 
 func branchGetSha(apiKey, owner, repo, ref string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs/heads/%s", owner, repo, ref)
-	req := pdk.NewHTTPRequest(pdk.MethodGet, url)
+	url := fmt.Sprintf("%s/repos/%s/%s/git/refs/heads/%s", apiBase, owner, repo, ref)
+	req := newRequest(pdk.MethodGet, url)
 	req.SetHeader("Authorization", fmt.Sprintf("token %s", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 200 {
 		return "", fmt.Errorf("Failed to get main branch sha: %d", resp.Status())
 	}
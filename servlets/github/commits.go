@@ -0,0 +1,454 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/extism/go-pdk"
+)
+
+var (
+	ListCommitsTool = ToolDescription{
+		Name:        "gh-list-commits",
+		Description: "List the commits of a GitHub repository, newest first, optionally filtered by path, author and date",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":     prop("string", "The owner of the repository"),
+				"repo":      prop("string", "The repository name"),
+				"sha":       prop("string", "Branch name, tag or commit SHA to start listing from (defaults to the default branch)"),
+				"path":      prop("string", "Only commits touching this file or directory path"),
+				"author":    prop("string", "Only commits by this GitHub login or email address"),
+				"committer": prop("string", "Only commits committed by this GitHub login or email address"),
+				"since":     prop("string", "Only commits after this ISO 8601 timestamp (YYYY-MM-DDTHH:MM:SSZ)"),
+				"until":     prop("string", "Only commits before this ISO 8601 timestamp (YYYY-MM-DDTHH:MM:SSZ)"),
+				"per_page":  prop("integer", "Number of results per page (max 100)"),
+				"page":      prop("integer", "Page number for pagination"),
+				"max_items": prop("integer", "Follow pagination and return up to this many results across pages (max 1000), with a cursor to continue from when truncated"),
+				"cursor":    prop("string", "The cursor returned by a previous call, to continue listing where it stopped"),
+			},
+			"required": []string{"owner", "repo"},
+		},
+	}
+	GetCommitTool = ToolDescription{
+		Name:        "gh-get-commit",
+		Description: "Get a commit of a GitHub repository, including its message, stats and the patch of each changed file",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":    prop("string", "The owner of the repository"),
+				"repo":     prop("string", "The repository name"),
+				"ref":      prop("string", "The commit SHA, branch or tag name"),
+				"per_page": prop("integer", "Number of changed files per page (max 100)"),
+				"page":     prop("integer", "Page number of the changed files"),
+			},
+			"required": []string{"owner", "repo", "ref"},
+		},
+	}
+	CompareTool = ToolDescription{
+		Name:        "gh-compare",
+		Description: "Compare two commits, branches or tags as base...head. Returns how far head is ahead of and behind base, the commits in head that are not in base, and the changed files",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":           prop("string", "The owner of the repository"),
+				"repo":            prop("string", "The repository name"),
+				"base":            prop("string", "The base branch, tag or commit SHA. Use owner:branch to compare across forks"),
+				"head":            prop("string", "The head branch, tag or commit SHA. Use owner:branch to compare across forks"),
+				"include_patches": prop("boolean", "Include the patch of each changed file (default true)"),
+			},
+			"required": []string{"owner", "repo", "base", "head"},
+		},
+	}
+	BlameTool = ToolDescription{
+		Name:        "gh-blame",
+		Description: "Show which commit last changed each line of a file, with the author, date and commit message",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":      prop("string", "The owner of the repository"),
+				"repo":       prop("string", "The repository name"),
+				"path":       prop("string", "The path of the file"),
+				"ref":        prop("string", "Branch name, tag or commit SHA (defaults to the default branch)"),
+				"start_line": prop("integer", "(optional) Only blame ranges ending at or after this line"),
+				"end_line":   prop("integer", "(optional) Only blame ranges starting at or before this line"),
+			},
+			"required": []string{"owner", "repo", "path"},
+		},
+	}
+	CommitTools = []ToolDescription{
+		ListCommitsTool,
+		GetCommitTool,
+		CompareTool,
+		BlameTool,
+	}
+)
+
+func commitsList(apiKey string, owner, repo string, args map[string]interface{}) (CallToolResult, error) {
+	baseURL := fmt.Sprintf("%s/repos/%s/%s/commits", apiBase, owner, repo)
+	params := url.Values{}
+
+	for _, key := range []string{"sha", "path", "author", "committer", "since", "until"} {
+		if value, ok := args[key].(string); ok && value != "" {
+			params.Set(key, value)
+		}
+	}
+
+	// Pagination parameters
+	perPage := 30 // Default value
+	if value, ok := args["per_page"].(float64); ok {
+		if value > 100 {
+			perPage = 100 // Max value
+		} else if value > 0 {
+			perPage = int(value)
+		}
+	}
+	params.Set("per_page", fmt.Sprint(perPage))
+
+	page := 1 // Default value
+	if value, ok := args["page"].(float64); ok && value > 0 {
+		page = int(value)
+	}
+	params.Set("page", fmt.Sprint(page))
+
+	url := fmt.Sprintf("%s?%s", baseURL, params.Encode())
+
+	if paginated(args) {
+		page, err := fetchPages[json.RawMessage](apiKey, url, "application/vnd.github+json", args)
+		return pageResult(page, err, "Failed to list commits"), nil
+	}
+
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Listing commits: ", url))
+
+	req := newRequest(pdk.MethodGet, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to list commits: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
+
+func commitGet(apiKey string, owner, repo, ref string, args map[string]interface{}) (CallToolResult, error) {
+	params := make([]string, 0)
+	if value, ok := args["per_page"].(float64); ok && value > 0 {
+		params = append(params, fmt.Sprintf("per_page=%d", min(int(value), 100)))
+	}
+	if value, ok := args["page"].(float64); ok && value > 0 {
+		params = append(params, fmt.Sprintf("page=%d", int(value)))
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s", apiBase, owner, repo, ref)
+	if len(params) > 0 {
+		url = fmt.Sprintf("%s?%s", url, strings.Join(params, "&"))
+	}
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Getting commit: ", url))
+
+	req := newRequest(pdk.MethodGet, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to get commit: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
+
+type ComparisonCommit struct {
+	Sha     string `json:"sha"`
+	Message string `json:"message"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+}
+
+type ComparisonFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename,omitempty"`
+	Status           string `json:"status"`
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	Changes          int    `json:"changes"`
+	Patch            string `json:"patch,omitempty"`
+}
+
+// Comparison holds the parts of a compare response that gh-compare returns.
+// The full response repeats the complete commit objects, which are rarely
+// needed.
+type Comparison struct {
+	Status          string `json:"status"`
+	AheadBy         int    `json:"ahead_by"`
+	BehindBy        int    `json:"behind_by"`
+	TotalCommits    int    `json:"total_commits"`
+	MergeBaseCommit struct {
+		Sha string `json:"sha"`
+	} `json:"merge_base_commit"`
+	HTMLURL string `json:"html_url"`
+	Commits []struct {
+		Sha    string `json:"sha"`
+		Commit struct {
+			Message string `json:"message"`
+			Author  Author `json:"author"`
+		} `json:"commit"`
+	} `json:"commits"`
+	Files []ComparisonFile `json:"files"`
+}
+
+func commitsCompare(apiKey string, owner, repo, base, head string, includePatches bool) (CallToolResult, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s", apiBase, owner, repo, base, head)
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Comparing commits: ", url))
+
+	req := newRequest(pdk.MethodGet, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to compare commits: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	var comparison Comparison
+	if err := json.Unmarshal(resp.Body(), &comparison); err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to parse comparison: %s", err)),
+			}},
+		}, nil
+	}
+
+	commits := make([]ComparisonCommit, 0, len(comparison.Commits))
+	for _, c := range comparison.Commits {
+		message, _, _ := strings.Cut(c.Commit.Message, "\n")
+		commits = append(commits, ComparisonCommit{
+			Sha:     c.Sha,
+			Message: message,
+			Author:  c.Commit.Author.Name,
+			Date:    c.Commit.Author.Date,
+		})
+	}
+	if !includePatches {
+		for i := range comparison.Files {
+			comparison.Files[i].Patch = ""
+		}
+	}
+
+	responseJSON, err := json.Marshal(map[string]interface{}{
+		"status":        comparison.Status,
+		"ahead_by":      comparison.AheadBy,
+		"behind_by":     comparison.BehindBy,
+		"total_commits": comparison.TotalCommits,
+		"merge_base":    comparison.MergeBaseCommit.Sha,
+		"html_url":      comparison.HTMLURL,
+		"commits":       commits,
+		"files":         comparison.Files,
+	})
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to marshal response: %s", err)),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(responseJSON)),
+		}},
+	}, nil
+}
+
+// blameQuery peels an annotated tag to the commit it points to, since only
+// commits have a blame.
+const blameQuery = `query($owner: String!, $repo: String!, $ref: String!, $path: String!) {
+  repository(owner: $owner, name: $repo) {
+    object(expression: $ref) {
+      __typename
+      ... on Commit { ...blameRanges }
+      ... on Tag {
+        target {
+          __typename
+          ... on Commit { ...blameRanges }
+        }
+      }
+    }
+  }
+}
+
+fragment blameRanges on Commit {
+  blame(path: $path) {
+    ranges {
+      startingLine
+      endingLine
+      age
+      commit {
+        oid
+        committedDate
+        messageHeadline
+        url
+        author { name user { login } }
+      }
+    }
+  }
+}`
+
+type BlameRange struct {
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Age       int    `json:"age"`
+	Sha       string `json:"sha"`
+	Author    string `json:"author"`
+	Login     string `json:"login,omitempty"`
+	Date      string `json:"date"`
+	Message   string `json:"message"`
+	URL       string `json:"url"`
+}
+
+func commitsBlame(apiKey string, owner, repo, path, ref string, startLine, endLine int) (CallToolResult, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	pdk.Log(pdk.LogDebug, fmt.Sprintf("Blaming %s/%s %s at %s", owner, repo, path, ref))
+
+	type blame struct {
+		Ranges []struct {
+			StartingLine int `json:"startingLine"`
+			EndingLine   int `json:"endingLine"`
+			Age          int `json:"age"`
+			Commit       struct {
+				Oid             string `json:"oid"`
+				CommittedDate   string `json:"committedDate"`
+				MessageHeadline string `json:"messageHeadline"`
+				URL             string `json:"url"`
+				Author          struct {
+					Name string `json:"name"`
+					User *struct {
+						Login string `json:"login"`
+					} `json:"user"`
+				} `json:"author"`
+			} `json:"commit"`
+		} `json:"ranges"`
+	}
+	var data struct {
+		Repository *struct {
+			Object *struct {
+				Typename string `json:"__typename"`
+				Blame    *blame `json:"blame"`
+				Target   *struct {
+					Typename string `json:"__typename"`
+					Blame    *blame `json:"blame"`
+				} `json:"target"`
+			} `json:"object"`
+		} `json:"repository"`
+	}
+	err := graphqlQuery(apiKey, blameQuery, map[string]interface{}{
+		"owner": owner,
+		"repo":  repo,
+		"ref":   ref,
+		"path":  path,
+	}, &data)
+	var commitBlame *blame
+	if err == nil {
+		switch {
+		case data.Repository == nil || data.Repository.Object == nil:
+			err = fmt.Errorf("ref %s not found in %s/%s", ref, owner, repo)
+		case data.Repository.Object.Blame != nil:
+			commitBlame = data.Repository.Object.Blame
+		case data.Repository.Object.Target != nil && data.Repository.Object.Target.Blame != nil:
+			commitBlame = data.Repository.Object.Target.Blame
+		case data.Repository.Object.Target != nil:
+			err = fmt.Errorf("ref %s points to a %s, not a commit", ref, data.Repository.Object.Target.Typename)
+		default:
+			err = fmt.Errorf("ref %s points to a %s, not a commit", ref, data.Repository.Object.Typename)
+		}
+	}
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to get blame: %s", err)),
+			}},
+		}, nil
+	}
+
+	ranges := make([]BlameRange, 0)
+	for _, r := range commitBlame.Ranges {
+		if (startLine > 0 && r.EndingLine < startLine) || (endLine > 0 && r.StartingLine > endLine) {
+			continue
+		}
+		br := BlameRange{
+			StartLine: r.StartingLine,
+			EndLine:   r.EndingLine,
+			Age:       r.Age,
+			Sha:       r.Commit.Oid,
+			Author:    r.Commit.Author.Name,
+			Date:      r.Commit.CommittedDate,
+			Message:   r.Commit.MessageHeadline,
+			URL:       r.Commit.URL,
+		}
+		if r.Commit.Author.User != nil {
+			br.Login = r.Commit.Author.User.Login
+		}
+		ranges = append(ranges, br)
+	}
+
+	responseJSON, err := json.Marshal(ranges)
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to marshal response: %s", err)),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(responseJSON)),
+		}},
+	}, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const defaultAPIBase = "https://api.github.com"
+
+var (
+	// apiBase is the REST API root without a trailing slash.
+	apiBase = defaultAPIBase
+	// graphqlURL is the GraphQL endpoint that belongs to apiBase.
+	graphqlURL = defaultAPIBase + "/graphql"
+	// uploadsBase is the root for release asset uploads.
+	uploadsBase = "https://uploads.github.com"
+	// apiVersion is sent as X-GitHub-Api-Version when configured. Older
+	// GitHub Enterprise Server releases reject unknown versions, so it is
+	// empty by default.
+	apiVersion = ""
+)
+
+// configureBaseURL derives the REST, GraphQL and upload endpoints from the
+// base-url config. It accepts the API root of github.com or a GHE.com
+// subdomain (https://api.example.ghe.com), or a GitHub Enterprise Server
+// host with or without the /api/v3 path (https://github.example.com/api/v3).
+func configureBaseURL(baseURL string) error {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" || baseURL == defaultAPIBase || baseURL == "https://github.com" {
+		apiBase = defaultAPIBase
+		graphqlURL = defaultAPIBase + "/graphql"
+		uploadsBase = "https://uploads.github.com"
+		return nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("Invalid base-url %q: expected an http(s) URL such as https://github.example.com/api/v3", baseURL)
+	}
+
+	// GHE.com serves the same layout as github.com under its own api. host.
+	// Other hosts are GitHub Enterprise Server, even when named api.
+	if strings.HasPrefix(u.Host, "api.") && strings.HasSuffix(u.Hostname(), ".ghe.com") && u.Path == "" {
+		apiBase = baseURL
+		graphqlURL = baseURL + "/graphql"
+		uploadsBase = u.Scheme + "://uploads." + strings.TrimPrefix(u.Host, "api.")
+		return nil
+	}
+
+	// GitHub Enterprise Server serves the REST API under /api/v3, GraphQL
+	// under /api/graphql and uploads under /api/uploads
+	root := u.Scheme + "://" + u.Host + strings.TrimSuffix(strings.TrimSuffix(u.Path, "/v3"), "/api")
+	apiBase = root + "/api/v3"
+	graphqlURL = root + "/api/graphql"
+	uploadsBase = root + "/api/uploads"
+	return nil
+}
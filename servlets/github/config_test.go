@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestConfigureBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL     string
+		wantAPI     string
+		wantGraphQL string
+		wantUploads string
+	}{
+		{"", "https://api.github.com", "https://api.github.com/graphql", "https://uploads.github.com"},
+		{"https://github.com/", "https://api.github.com", "https://api.github.com/graphql", "https://uploads.github.com"},
+		{"https://api.octocorp.ghe.com", "https://api.octocorp.ghe.com", "https://api.octocorp.ghe.com/graphql", "https://uploads.octocorp.ghe.com"},
+		{"https://github.example.com", "https://github.example.com/api/v3", "https://github.example.com/api/graphql", "https://github.example.com/api/uploads"},
+		{"https://github.example.com/api/v3/", "https://github.example.com/api/v3", "https://github.example.com/api/graphql", "https://github.example.com/api/uploads"},
+		{"https://api.corp.example", "https://api.corp.example/api/v3", "https://api.corp.example/api/graphql", "https://api.corp.example/api/uploads"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			if err := configureBaseURL(tt.baseURL); err != nil {
+				t.Fatalf("configureBaseURL() error: %s", err)
+			}
+			defer configureBaseURL("")
+
+			if apiBase != tt.wantAPI || graphqlURL != tt.wantGraphQL || uploadsBase != tt.wantUploads {
+				t.Errorf("configureBaseURL() = %s, %s, %s, want %s, %s, %s",
+					apiBase, graphqlURL, uploadsBase, tt.wantAPI, tt.wantGraphQL, tt.wantUploads)
+			}
+		})
+	}
+
+	if err := configureBaseURL("ftp://github.example.com"); err == nil {
+		t.Error("configureBaseURL() accepted an ftp URL")
+	}
+}
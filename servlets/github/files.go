@@ -108,8 +108,8 @@ func filesCreateOrUpdate(apiKey string, owner string, repo string, path string,
 		}
 	}
 
-	url := fmt.Sprint("https://api.github.com/repos/", owner, "/", repo, "/contents/", path)
-	req := pdk.NewHTTPRequest(pdk.MethodPut, url)
+	url := fmt.Sprint(apiBase, "/repos/", owner, "/", repo, "/contents/", path)
+	req := newRequest(pdk.MethodPut, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
@@ -126,7 +126,7 @@ func filesCreateOrUpdate(apiKey string, owner string, repo string, path string,
 	}
 
 	req.SetBody([]byte(res))
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 201 {
 		return CallToolResult{
 			IsError: some(true),
@@ -206,7 +206,7 @@ func filesGetContents(apiKey string, owner string, repo string, path string, bra
 }
 
 func filesGetContentsInternal(apiKey string, owner string, repo string, path string, branch *string) (UnionContent, error) {
-	u := fmt.Sprint("https://api.github.com/repos/", owner, "/", repo, "/contents/", path)
+	u := fmt.Sprint(apiBase, "/repos/", owner, "/", repo, "/contents/", path)
 
 	params := url.Values{}
 	if branch != nil {
@@ -214,12 +214,12 @@ func filesGetContentsInternal(apiKey string, owner string, repo string, path str
 	}
 	u = fmt.Sprint(u, "?", params.Encode())
 
-	req := pdk.NewHTTPRequest(pdk.MethodGet, u)
+	req := newRequest(pdk.MethodGet, u)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 200 {
 		return UnionContent{}, fmt.Errorf("Failed to get file contents: %d %s (%s)", resp.Status(), string(resp.Body()), u)
 	}
@@ -265,13 +265,13 @@ func filePushFromArgs(args map[string]interface{}) []FileOperation {
 }
 
 func filesPush(apiKey, owner, repo, branch, message string, files []FileOperation) CallToolResult {
-	url := fmt.Sprint("https://api.github.com/repos/", owner, "/", repo, "/git/refs/heads/", branch)
-	req := pdk.NewHTTPRequest(pdk.MethodGet, url)
+	url := fmt.Sprint(apiBase, "/repos/", owner, "/", repo, "/git/refs/heads/", branch)
+	req := newRequest(pdk.MethodGet, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 200 {
 		return CallToolResult{
 			IsError: some(true),
@@ -335,8 +335,8 @@ func createTree(apiKey, owner, repo string, files []FileOperation, baseTree stri
 			Path: file.Path, Mode: "100644", Type: "blob", Content: file.Content})
 	}
 
-	url := fmt.Sprint("https://api.github.com/repos/", owner, "/", repo, "/git/trees")
-	req := pdk.NewHTTPRequest(pdk.MethodPost, url)
+	url := fmt.Sprint(apiBase, "/repos/", owner, "/", repo, "/git/trees")
+	req := newRequest(pdk.MethodPost, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
@@ -349,7 +349,7 @@ func createTree(apiKey, owner, repo string, files []FileOperation, baseTree stri
 	}
 	req.SetBody(res)
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 201 {
 		return TreeSchema{}, fmt.Errorf("Failed to create tree: %d %s", resp.Status(), string(resp.Body()))
 	}
@@ -389,8 +389,8 @@ func createCommit(apiKey, owner, repo, message, tree string, parents []string) (
 		"parents": parents,
 	}
 
-	url := fmt.Sprint("https://api.github.com/repos/", owner, "/", repo, "/git/commits")
-	req := pdk.NewHTTPRequest(pdk.MethodPost, url)
+	url := fmt.Sprint(apiBase, "/repos/", owner, "/", repo, "/git/commits")
+	req := newRequest(pdk.MethodPost, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
@@ -399,7 +399,7 @@ func createCommit(apiKey, owner, repo, message, tree string, parents []string) (
 	res, _ := json.Marshal(commit)
 	req.SetBody(res)
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 201 {
 		return Commit{}, fmt.Errorf("Failed to create commit: %d %s", resp.Status(), string(resp.Body()))
 	}
@@ -410,8 +410,8 @@ func createCommit(apiKey, owner, repo, message, tree string, parents []string) (
 }
 
 func updateRef(apiKey, owner, repo, ref, sha string) CallToolResult {
-	url := fmt.Sprint("https://api.github.com/repos/", owner, "/", repo, "/git/refs/", ref)
-	req := pdk.NewHTTPRequest(pdk.MethodPatch, url)
+	url := fmt.Sprint(apiBase, "/repos/", owner, "/", repo, "/git/refs/", ref)
+	req := newRequest(pdk.MethodPatch, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
@@ -420,7 +420,7 @@ func updateRef(apiKey, owner, repo, ref, sha string) CallToolResult {
 	res, _ := json.Marshal(map[string]any{"sha": sha, "force": true})
 	req.SetBody(res)
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 200 {
 		return CallToolResult{
 			IsError: some(true),
@@ -90,8 +90,8 @@ var GistTools = []ToolDescription{
 }
 
 func gistCreate(apiKey, description string, files map[string]any) CallToolResult {
-	url := "https://api.github.com/gists"
-	req := pdk.NewHTTPRequest(pdk.MethodPost, url)
+	url := apiBase + "/gists"
+	req := newRequest(pdk.MethodPost, url)
 	req.SetHeader("Authorization", fmt.Sprintf("token %s", apiKey))
 	req.SetHeader("Content-Type", "application/json")
 	req.SetHeader("Accept", "application/vnd.github+json")
@@ -112,7 +112,7 @@ func gistCreate(apiKey, description string, files map[string]any) CallToolResult
 		}
 	}
 	req.SetBody(res)
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 201 {
 		return CallToolResult{
 			IsError: some(true),
@@ -132,8 +132,8 @@ func gistCreate(apiKey, description string, files map[string]any) CallToolResult
 }
 
 func gistUpdate(apiKey, gistId, description string, files map[string]any) CallToolResult {
-	url := fmt.Sprintf("https://api.github.com/gists/%s", gistId)
-	req := pdk.NewHTTPRequest(pdk.MethodPatch, url)
+	url := fmt.Sprintf("%s/gists/%s", apiBase, gistId)
+	req := newRequest(pdk.MethodPatch, url)
 	req.SetHeader("Authorization", fmt.Sprintf("token %s", apiKey))
 	req.SetHeader("Content-Type", "application/json")
 	req.SetHeader("Accept", "application/vnd.github+json")
@@ -154,7 +154,7 @@ func gistUpdate(apiKey, gistId, description string, files map[string]any) CallTo
 		}
 	}
 	req.SetBody(res)
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 201 {
 		return CallToolResult{
 			IsError: some(true),
@@ -174,14 +174,14 @@ func gistUpdate(apiKey, gistId, description string, files map[string]any) CallTo
 }
 
 func gistGet(apiKey, gistId string) CallToolResult {
-	url := fmt.Sprintf("https://api.github.com/gists/%s", gistId)
-	req := pdk.NewHTTPRequest(pdk.MethodGet, url)
+	url := fmt.Sprintf("%s/gists/%s", apiBase, gistId)
+	req := newRequest(pdk.MethodGet, url)
 	req.SetHeader("Authorization", fmt.Sprintf("token %s", apiKey))
 	req.SetHeader("Content-Type", "application/json")
 	req.SetHeader("Accept", "application/vnd.github+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 201 {
 		return CallToolResult{
 			IsError: some(true),
@@ -201,14 +201,14 @@ func gistGet(apiKey, gistId string) CallToolResult {
 }
 
 func gistDelete(apiKey, gistId string) CallToolResult {
-	url := fmt.Sprintf("https://api.github.com/gists/%s", gistId)
-	req := pdk.NewHTTPRequest(pdk.MethodDelete, url)
+	url := fmt.Sprintf("%s/gists/%s", apiBase, gistId)
+	req := newRequest(pdk.MethodDelete, url)
 	req.SetHeader("Authorization", fmt.Sprintf("token %s", apiKey))
 	req.SetHeader("Content-Type", "application/json")
 	req.SetHeader("Accept", "application/vnd.github+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 201 {
 		return CallToolResult{
 			IsError: some(true),
@@ -2,4 +2,4 @@ module github
 
 go 1.23
 
-require github.com/extism/go-pdk v1.0.5
+require github.com/extism/go-pdk v1.1.3
@@ -29,6 +29,8 @@ var (
 				"pulls":     prop("boolean", "Include pull requests in results"),
 				"per_page":  prop("integer", "Number of results per page (max 100)"),
 				"page":      prop("integer", "Page number for pagination"),
+				"max_items": prop("integer", "Follow pagination and return up to this many results across pages (max 1000), with a cursor to continue from when truncated"),
+				"cursor":    prop("string", "The cursor returned by a previous call, to continue listing where it stopped"),
 			},
 			"required": []string{"owner", "repo"},
 		},
@@ -113,7 +115,7 @@ type Issue struct {
 }
 
 func issueList(apiKey string, owner, repo string, args map[string]interface{}) (CallToolResult, error) {
-	baseURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues", owner, repo)
+	baseURL := fmt.Sprintf("%s/repos/%s/%s/issues", apiBase, owner, repo)
 	params := make([]string, 0)
 
 	// String parameters
@@ -166,15 +168,20 @@ func issueList(apiKey string, owner, repo string, args map[string]interface{}) (
 		url = fmt.Sprintf("%s?%s", baseURL, strings.Join(params, "&"))
 	}
 
+	if paginated(args) {
+		page, err := fetchPages[json.RawMessage](apiKey, url, "application/vnd.github+json", args)
+		return pageResult(page, err, "Failed to list issues"), nil
+	}
+
 	pdk.Log(pdk.LogDebug, fmt.Sprint("Listing issues: ", url))
 
 	// Make request
-	req := pdk.NewHTTPRequest(pdk.MethodGet, url)
+	req := newRequest(pdk.MethodGet, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 200 {
 		return CallToolResult{
 			IsError: some(true),
@@ -218,10 +225,10 @@ func issueFromArgs(args map[string]interface{}) Issue {
 }
 
 func issueCreate(apiKey string, owner, repo string, data Issue) (CallToolResult, error) {
-	url := fmt.Sprint("https://api.github.com/repos/", owner, "/", repo, "/issues")
+	url := fmt.Sprint(apiBase, "/repos/", owner, "/", repo, "/issues")
 	pdk.Log(pdk.LogDebug, fmt.Sprint("Adding comment: ", url))
 
-	req := pdk.NewHTTPRequest(pdk.MethodPost, url)
+	req := newRequest(pdk.MethodPost, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
@@ -240,7 +247,7 @@ func issueCreate(apiKey string, owner, repo string, data Issue) (CallToolResult,
 	}
 
 	req.SetBody([]byte(res))
-	resp := req.Send()
+	resp := send(req)
 
 	if resp.Status() != 201 {
 		return CallToolResult{
@@ -261,14 +268,14 @@ func issueCreate(apiKey string, owner, repo string, data Issue) (CallToolResult,
 }
 
 func issueGet(apiKey string, owner, repo string, issue int) (CallToolResult, error) {
-	url := fmt.Sprint("https://api.github.com/repos/", owner, "/", repo, "/issues/", issue)
+	url := fmt.Sprint(apiBase, "/repos/", owner, "/", repo, "/issues/", issue)
 	pdk.Log(pdk.LogDebug, fmt.Sprint("Getting issue: ", url))
 
-	req := pdk.NewHTTPRequest(pdk.MethodGet, url)
+	req := newRequest(pdk.MethodGet, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 200 {
 		return CallToolResult{
 			IsError: some(true),
@@ -288,10 +295,10 @@ func issueGet(apiKey string, owner, repo string, issue int) (CallToolResult, err
 }
 
 func issueUpdate(apiKey string, owner, repo string, issue int, data Issue) (CallToolResult, error) {
-	url := fmt.Sprint("https://api.github.com/repos/", owner, "/", repo, "/issues/", issue)
+	url := fmt.Sprint(apiBase, "/repos/", owner, "/", repo, "/issues/", issue)
 	pdk.Log(pdk.LogDebug, fmt.Sprint("Getting issue: ", url))
 
-	req := pdk.NewHTTPRequest(pdk.MethodPatch, url)
+	req := newRequest(pdk.MethodPatch, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
@@ -309,7 +316,7 @@ func issueUpdate(apiKey string, owner, repo string, issue int, data Issue) (Call
 	}
 
 	req.SetBody([]byte(res))
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 200 {
 		return CallToolResult{
 			IsError: some(true),
@@ -329,10 +336,10 @@ func issueUpdate(apiKey string, owner, repo string, issue int, data Issue) (Call
 }
 
 func issueAddComment(apiKey string, owner, repo string, issue int, comment string) (CallToolResult, error) {
-	url := fmt.Sprint("https://api.github.com/repos/", owner, "/", repo, "/issues/", issue, "/comments")
+	url := fmt.Sprint(apiBase, "/repos/", owner, "/", repo, "/issues/", issue, "/comments")
 	pdk.Log(pdk.LogDebug, fmt.Sprint("Adding comment: ", url))
 
-	req := pdk.NewHTTPRequest(pdk.MethodPost, url)
+	req := newRequest(pdk.MethodPost, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github.v3+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
@@ -353,7 +360,7 @@ func issueAddComment(apiKey string, owner, repo string, issue int, comment strin
 	}
 
 	req.SetBody([]byte(res))
-	resp := req.Send()
+	resp := send(req)
 
 	if resp.Status() != 201 {
 		return CallToolResult{
@@ -14,19 +14,38 @@ import (
 // It takes CallToolRequest as input (The incoming tool request from the LLM)
 // And returns CallToolResult (The servlet's response to the given tool call)
 func Call(input CallToolRequest) (CallToolResult, error) {
-	apiKey, ok := pdk.GetConfig("api-key")
-	if !ok {
+	baseURL, _ := pdk.GetConfig("base-url")
+	if err := configureBaseURL(baseURL); err != nil {
 		return CallToolResult{
 			IsError: some(true),
 			Content: []Content{{
 				Type: ContentTypeText,
-				Text: some("No api-key configured"),
+				Text: some(err.Error()),
 			}},
 		}, nil
 	}
+	apiVersion, _ = pdk.GetConfig("api-version")
+	lastRateLimit = nil
+
+	apiKey, err := resolveToken()
+	if err != nil {
+		return withRateLimit(CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(err.Error()),
+			}},
+		}), nil
+	}
+
 	args := input.Params.Arguments.(map[string]interface{})
 	pdk.Log(pdk.LogDebug, fmt.Sprint("Args: ", args))
-	switch input.Params.Name {
+	result, err := callTool(apiKey, input.Params.Name, args)
+	return withRateLimit(result), err
+}
+
+func callTool(apiKey, name string, args map[string]interface{}) (CallToolResult, error) {
+	switch name {
 	case ListIssuesTool.Name:
 		owner, _ := args["owner"].(string)
 		repo, _ := args["repo"].(string)
@@ -89,6 +108,61 @@ func Call(input CallToolRequest) (CallToolResult, error) {
 		pr := branchPullRequestSchemaFromArgs(args)
 		return branchCreatePullRequest(apiKey, owner, repo, pr), nil
 
+	case GetPullRequestDiffTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		pullNumber, _ := args["pull_number"].(float64)
+		return pullRequestGetDiff(apiKey, owner, repo, int(pullNumber))
+
+	case ListPullRequestFilesTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		pullNumber, _ := args["pull_number"].(float64)
+		return pullRequestListFiles(apiKey, owner, repo, int(pullNumber), args)
+
+	case ListPullRequestReviewCommentsTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		pullNumber, _ := args["pull_number"].(float64)
+		return pullRequestListReviewComments(apiKey, owner, repo, int(pullNumber), args)
+
+	case CreatePullRequestReviewTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		pullNumber, _ := args["pull_number"].(float64)
+		review := reviewFromArgs(args)
+		return pullRequestCreateReview(apiKey, owner, repo, int(pullNumber), review)
+
+	case SubmitPullRequestReviewTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		pullNumber, _ := args["pull_number"].(float64)
+		reviewID, _ := args["review_id"].(float64)
+		event, _ := args["event"].(string)
+		body, _ := args["body"].(string)
+		return pullRequestSubmitReview(apiKey, owner, repo, int(pullNumber), int(reviewID), event, body)
+
+	case MergePullRequestTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		pullNumber, _ := args["pull_number"].(float64)
+		merge := pullRequestMergeFromArgs(args)
+		return pullRequestMerge(apiKey, owner, repo, int(pullNumber), merge)
+
+	case UpdatePullRequestTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		pullNumber, _ := args["pull_number"].(float64)
+		update := pullRequestUpdateFromArgs(args)
+		return pullRequestUpdate(apiKey, owner, repo, int(pullNumber), update)
+
+	case UpdatePullRequestBranchTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		pullNumber, _ := args["pull_number"].(float64)
+		expectedHeadSha, _ := args["expected_head_sha"].(string)
+		return pullRequestUpdateBranch(apiKey, owner, repo, int(pullNumber), expectedHeadSha)
+
 	case PushFilesTool.Name:
 		owner, _ := args["owner"].(string)
 		repo, _ := args["repo"].(string)
@@ -116,6 +190,107 @@ func Call(input CallToolRequest) (CallToolResult, error) {
 		repo, _ := args["repo"].(string)
 		return reposGetDetails(apiKey, owner, repo)
 
+	case ListCommitsTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		return commitsList(apiKey, owner, repo, args)
+
+	case GetCommitTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		ref, _ := args["ref"].(string)
+		return commitGet(apiKey, owner, repo, ref, args)
+
+	case CompareTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		base, _ := args["base"].(string)
+		head, _ := args["head"].(string)
+		includePatches := true
+		if value, ok := args["include_patches"].(bool); ok {
+			includePatches = value
+		}
+		return commitsCompare(apiKey, owner, repo, base, head, includePatches)
+
+	case BlameTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		path, _ := args["path"].(string)
+		ref, _ := args["ref"].(string)
+		startLine, _ := args["start_line"].(float64)
+		endLine, _ := args["end_line"].(float64)
+		return commitsBlame(apiKey, owner, repo, path, ref, int(startLine), int(endLine))
+
+	case ListReleasesTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		return releasesList(apiKey, owner, repo, args)
+
+	case GetReleaseTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		releaseID, _ := args["release_id"].(float64)
+		tag, _ := args["tag"].(string)
+		return releaseGet(apiKey, owner, repo, int(releaseID), tag)
+
+	case CreateReleaseTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		release := releaseFromArgs(args)
+		previousTag, _ := args["previous_tag_name"].(string)
+		return releaseCreate(apiKey, owner, repo, release, previousTag)
+
+	case UpdateReleaseTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		releaseID, _ := args["release_id"].(float64)
+		release := releaseFromArgs(args)
+		return releaseUpdate(apiKey, owner, repo, int(releaseID), release)
+
+	case DeleteReleaseTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		releaseID, _ := args["release_id"].(float64)
+		return releaseDelete(apiKey, owner, repo, int(releaseID))
+
+	case CreateTagTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		tag, _ := args["tag"].(string)
+		sha, _ := args["sha"].(string)
+		message, _ := args["message"].(string)
+		taggerName, _ := args["tagger_name"].(string)
+		taggerEmail, _ := args["tagger_email"].(string)
+		return tagCreate(apiKey, owner, repo, tag, sha, message, taggerName, taggerEmail), nil
+
+	case UploadReleaseAssetTool.Name:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		releaseID, _ := args["release_id"].(float64)
+		asset, err := releaseAssetFromArgs(args)
+		if err != nil {
+			return CallToolResult{
+				IsError: some(true),
+				Content: []Content{{
+					Type: ContentTypeText,
+					Text: some(err.Error()),
+				}},
+			}, nil
+		}
+		return releaseUploadAsset(apiKey, owner, repo, int(releaseID), asset), nil
+
+	case SearchCodeTool.Name:
+		return searchCode(apiKey, args), nil
+
+	case SearchIssuesTool.Name:
+		return searchIssues(apiKey, args), nil
+
+	case SearchCommitsTool.Name:
+		return searchCommits(apiKey, args), nil
+
+	case SearchRepositoriesTool.Name:
+		return searchRepositories(apiKey, args), nil
+
 	case CreateGistTool.Name:
 		description, _ := args["description"].(string)
 		files, _ := args["files"].(map[string]any)
@@ -140,11 +315,10 @@ func Call(input CallToolRequest) (CallToolResult, error) {
 			IsError: some(true),
 			Content: []Content{{
 				Type: ContentTypeText,
-				Text: some("Unknown tool " + input.Params.Name),
+				Text: some("Unknown tool " + name),
 			}},
 		}, nil
 	}
-
 }
 
 func Describe() (ListToolsResult, error) {
@@ -152,8 +326,12 @@ func Describe() (ListToolsResult, error) {
 		IssueTools,
 		FileTools,
 		BranchTools,
+		PullRequestTools,
 		RepoTools,
 		GistTools,
+		SearchTools,
+		CommitTools,
+		ReleaseTools,
 	}
 
 	tools := []ToolDescription{}
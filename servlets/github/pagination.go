@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/extism/go-pdk"
+)
+
+// Page is a list of results collected by following `Link: rel="next"`
+// headers. Cursor is set when max_items stopped the listing early and can
+// be passed back to continue from the next item.
+type Page[T any] struct {
+	Items  []T    `json:"items"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type pageCursor struct {
+	URL  string `json:"url"`
+	Skip int    `json:"skip,omitempty"`
+}
+
+// paginated reports whether a list tool was asked to collect multiple pages.
+// Without max_items or cursor, list tools return the single requested page.
+func paginated(args map[string]interface{}) bool {
+	_, hasMaxItems := args["max_items"].(float64)
+	cursor, _ := args["cursor"].(string)
+	return hasMaxItems || cursor != ""
+}
+
+func encodeCursor(c pageCursor) string {
+	res, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(res)
+}
+
+func decodeCursor(cursor string) (pageCursor, error) {
+	c := pageCursor{}
+	res, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, fmt.Errorf("Invalid cursor: %w", err)
+	}
+	if err := json.Unmarshal(res, &c); err != nil {
+		return c, fmt.Errorf("Invalid cursor: %w", err)
+	}
+	// the cursor is sent back by the caller, never follow it to another host
+	if !strings.HasPrefix(c.URL, apiBase+"/") {
+		return c, fmt.Errorf("Invalid cursor: unexpected URL %s", c.URL)
+	}
+	return c, nil
+}
+
+// nextLink returns the rel="next" URL of a Link header, if any.
+func nextLink(headers map[string]string) string {
+	for _, part := range strings.Split(header(headers, "Link"), ",") {
+		segments := strings.Split(part, ";")
+		if len(segments) < 2 {
+			continue
+		}
+		for _, param := range segments[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(segments[0]), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// fetchPages collects up to max_items results starting at firstURL, or at
+// the position stored in the cursor argument.
+func fetchPages[T any](apiKey, firstURL, accept string, args map[string]interface{}) (Page[T], error) {
+	maxItems := 100 // Default value
+	if value, ok := args["max_items"].(float64); ok && value > 0 {
+		maxItems = min(int(value), 1000) // Max value
+	}
+
+	next := firstURL
+	skip := 0
+	if cursor, ok := args["cursor"].(string); ok && cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		next, skip = c.URL, c.Skip
+	} else if _, ok := args["per_page"].(float64); !ok {
+		// use the largest page size to save requests
+		if u, err := url.Parse(firstURL); err == nil {
+			q := u.Query()
+			q.Set("per_page", fmt.Sprint(min(maxItems, 100)))
+			u.RawQuery = q.Encode()
+			next = u.String()
+		}
+	}
+
+	page := Page[T]{Items: []T{}}
+	for next != "" && len(page.Items) < maxItems {
+		pdk.Log(pdk.LogDebug, fmt.Sprint("Fetching page: ", next))
+
+		req := newRequest(pdk.MethodGet, next)
+		req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+		req.SetHeader("Accept", accept)
+		req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+		resp := send(req)
+		if resp.Status() != 200 {
+			return Page[T]{}, fmt.Errorf("%d %s", resp.Status(), string(resp.Body()))
+		}
+
+		var items []T
+		if err := json.Unmarshal(resp.Body(), &items); err != nil {
+			return Page[T]{}, fmt.Errorf("Failed to parse page: %w", err)
+		}
+		if skip > len(items) {
+			skip = len(items)
+		}
+		items = items[skip:]
+
+		remaining := maxItems - len(page.Items)
+		if len(items) > remaining {
+			// stop in the middle of this page and remember where
+			page.Items = append(page.Items, items[:remaining]...)
+			page.Cursor = encodeCursor(pageCursor{URL: next, Skip: skip + remaining})
+			return page, nil
+		}
+		page.Items = append(page.Items, items...)
+		skip = 0
+		next = nextLink(resp.Headers())
+	}
+
+	if next != "" {
+		page.Cursor = encodeCursor(pageCursor{URL: next})
+	}
+	return page, nil
+}
+
+// pageResult converts the outcome of fetchPages into a tool result, using
+// message to describe a failure.
+func pageResult[T any](page Page[T], err error, message string) CallToolResult {
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("%s: %s", message, err)),
+			}},
+		}
+	}
+
+	res, err := json.Marshal(page)
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to marshal response: %s", err)),
+			}},
+		}
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(res)),
+		}},
+	}
+}
@@ -0,0 +1,714 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/extism/go-pdk"
+)
+
+var (
+	GetPullRequestDiffTool = ToolDescription{
+		Name:        "gh-get-pull-request-diff",
+		Description: "Get the unified diff of a pull request in a GitHub repository",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":       prop("string", "The owner of the repository"),
+				"repo":        prop("string", "The repository name"),
+				"pull_number": prop("integer", "The pull request number"),
+			},
+			"required": []string{"owner", "repo", "pull_number"},
+		},
+	}
+	ListPullRequestFilesTool = ToolDescription{
+		Name:        "gh-list-pull-request-files",
+		Description: "List the files changed by a pull request, including their status, additions, deletions and patch",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":       prop("string", "The owner of the repository"),
+				"repo":        prop("string", "The repository name"),
+				"pull_number": prop("integer", "The pull request number"),
+				"per_page":    prop("integer", "Number of results per page (max 100)"),
+				"page":        prop("integer", "Page number for pagination"),
+			},
+			"required": []string{"owner", "repo", "pull_number"},
+		},
+	}
+	ListPullRequestReviewCommentsTool = ToolDescription{
+		Name:        "gh-list-pull-request-review-comments",
+		Description: "List the review comments on the diff of a pull request",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":       prop("string", "The owner of the repository"),
+				"repo":        prop("string", "The repository name"),
+				"pull_number": prop("integer", "The pull request number"),
+				"sort":        prop("string", "Sort field (created, updated)"),
+				"direction":   prop("string", "Sort direction (asc or desc)"),
+				"since":       prop("string", "Only comments updated after this ISO 8601 timestamp (YYYY-MM-DDTHH:MM:SSZ)"),
+				"per_page":    prop("integer", "Number of results per page (max 100)"),
+				"page":        prop("integer", "Page number for pagination"),
+			},
+			"required": []string{"owner", "repo", "pull_number"},
+		},
+	}
+	CreatePullRequestReviewTool = ToolDescription{
+		Name:        "gh-create-pull-request-review",
+		Description: "Create a review on a pull request with optional comments anchored to lines of the diff. Without an event the review stays pending until it is submitted with gh-submit-pull-request-review",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":       prop("string", "The owner of the repository"),
+				"repo":        prop("string", "The repository name"),
+				"pull_number": prop("integer", "The pull request number"),
+				"body":        prop("string", "The body text of the review"),
+				"event":       prop("string", "The review action: APPROVE, REQUEST_CHANGES or COMMENT. Leave empty to create a pending review"),
+				"commit_id":   prop("string", "(optional) The SHA of the commit to review, defaults to the latest commit of the pull request"),
+				"comments": SchemaProperty{
+					Type:        "array",
+					Description: "Comments to place on lines of the diff",
+					Items: &schema{
+						"type": "object",
+						"properties": props{
+							"path":       prop("string", "The path of the file to comment on"),
+							"line":       prop("integer", "The line of the file to comment on. For multi-line comments, the last line of the range"),
+							"side":       prop("string", "The side of the diff the line is on: RIGHT for additions and context (default), LEFT for deletions"),
+							"start_line": prop("integer", "(optional) The first line of a multi-line comment"),
+							"start_side": prop("string", "(optional) The side of the diff start_line is on"),
+							"body":       prop("string", "The text of the comment"),
+						},
+						"required": []string{"path", "line", "body"},
+					},
+				},
+			},
+			"required": []string{"owner", "repo", "pull_number"},
+		},
+	}
+	SubmitPullRequestReviewTool = ToolDescription{
+		Name:        "gh-submit-pull-request-review",
+		Description: "Submit a pending pull request review as APPROVE, REQUEST_CHANGES or COMMENT",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":       prop("string", "The owner of the repository"),
+				"repo":        prop("string", "The repository name"),
+				"pull_number": prop("integer", "The pull request number"),
+				"review_id":   prop("integer", "The id of the pending review"),
+				"event":       prop("string", "The review action: APPROVE, REQUEST_CHANGES or COMMENT"),
+				"body":        prop("string", "The body text of the review, required for REQUEST_CHANGES and COMMENT"),
+			},
+			"required": []string{"owner", "repo", "pull_number", "review_id", "event"},
+		},
+	}
+	MergePullRequestTool = ToolDescription{
+		Name:        "gh-merge-pull-request",
+		Description: "Merge a pull request in a GitHub repository",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":          prop("string", "The owner of the repository"),
+				"repo":           prop("string", "The repository name"),
+				"pull_number":    prop("integer", "The pull request number"),
+				"merge_method":   prop("string", "The merge method: merge (default), squash or rebase"),
+				"commit_title":   prop("string", "(optional) Title for the merge or squash commit"),
+				"commit_message": prop("string", "(optional) Extra detail for the merge or squash commit"),
+				"sha":            prop("string", "(optional) The SHA the head of the pull request must match for the merge to happen"),
+			},
+			"required": []string{"owner", "repo", "pull_number"},
+		},
+	}
+	UpdatePullRequestTool = ToolDescription{
+		Name:        "gh-update-pull-request",
+		Description: "Update the title, body, base branch or state of a pull request, close or reopen it, or toggle it between draft and ready for review",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":                 prop("string", "The owner of the repository"),
+				"repo":                  prop("string", "The repository name"),
+				"pull_number":           prop("integer", "The pull request number"),
+				"title":                 prop("string", "The title of the pull request"),
+				"body":                  prop("string", "The body of the pull request"),
+				"base":                  prop("string", "The branch the pull request should be merged into"),
+				"state":                 prop("string", "The state of the pull request (open or closed)"),
+				"draft":                 prop("boolean", "true to convert the pull request to a draft, false to mark it ready for review"),
+				"maintainer_can_modify": prop("boolean", "Allow maintainers to modify the pull request"),
+			},
+			"required": []string{"owner", "repo", "pull_number"},
+		},
+	}
+	UpdatePullRequestBranchTool = ToolDescription{
+		Name:        "gh-update-pull-request-branch",
+		Description: "Update the head branch of a pull request with the latest changes from its base branch",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":             prop("string", "The owner of the repository"),
+				"repo":              prop("string", "The repository name"),
+				"pull_number":       prop("integer", "The pull request number"),
+				"expected_head_sha": prop("string", "(optional) The SHA the head of the pull request must match for the update to happen"),
+			},
+			"required": []string{"owner", "repo", "pull_number"},
+		},
+	}
+	PullRequestTools = []ToolDescription{
+		GetPullRequestDiffTool,
+		ListPullRequestFilesTool,
+		ListPullRequestReviewCommentsTool,
+		CreatePullRequestReviewTool,
+		SubmitPullRequestReviewTool,
+		MergePullRequestTool,
+		UpdatePullRequestTool,
+		UpdatePullRequestBranchTool,
+	}
+)
+
+type ReviewComment struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Side      string `json:"side,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+	Body      string `json:"body"`
+}
+
+type Review struct {
+	Body     string          `json:"body,omitempty"`
+	Event    string          `json:"event,omitempty"`
+	CommitID string          `json:"commit_id,omitempty"`
+	Comments []ReviewComment `json:"comments,omitempty"`
+}
+
+func reviewFromArgs(args map[string]interface{}) Review {
+	review := Review{}
+	if body, ok := args["body"].(string); ok {
+		review.Body = body
+	}
+	if event, ok := args["event"].(string); ok {
+		review.Event = strings.ToUpper(event)
+	}
+	if commitID, ok := args["commit_id"].(string); ok {
+		review.CommitID = commitID
+	}
+	if comments, ok := args["comments"].([]interface{}); ok {
+		for _, c := range comments {
+			if c, ok := c.(map[string]interface{}); ok {
+				comment := ReviewComment{}
+				comment.Path, _ = c["path"].(string)
+				comment.Body, _ = c["body"].(string)
+				comment.Side, _ = c["side"].(string)
+				comment.StartSide, _ = c["start_side"].(string)
+				if line, ok := c["line"].(float64); ok {
+					comment.Line = int(line)
+				}
+				if startLine, ok := c["start_line"].(float64); ok {
+					comment.StartLine = int(startLine)
+				}
+				review.Comments = append(review.Comments, comment)
+			}
+		}
+	}
+	return review
+}
+
+func pullRequestGetDiff(apiKey string, owner, repo string, pullNumber int) (CallToolResult, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", apiBase, owner, repo, pullNumber)
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Getting pull request diff: ", url))
+
+	req := newRequest(pdk.MethodGet, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github.diff")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to get pull request diff: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
+
+func pullRequestListFiles(apiKey string, owner, repo string, pullNumber int, args map[string]interface{}) (CallToolResult, error) {
+	baseURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/files", apiBase, owner, repo, pullNumber)
+	params := make([]string, 0)
+
+	// Pagination parameters
+	perPage := 30 // Default value
+	if value, ok := args["per_page"].(float64); ok {
+		if value > 100 {
+			perPage = 100 // Max value
+		} else if value > 0 {
+			perPage = int(value)
+		}
+	}
+	params = append(params, fmt.Sprintf("per_page=%d", perPage))
+
+	page := 1 // Default value
+	if value, ok := args["page"].(float64); ok && value > 0 {
+		page = int(value)
+	}
+	params = append(params, fmt.Sprintf("page=%d", page))
+
+	url := fmt.Sprintf("%s?%s", baseURL, strings.Join(params, "&"))
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Listing pull request files: ", url))
+
+	req := newRequest(pdk.MethodGet, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to list pull request files: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
+
+func pullRequestListReviewComments(apiKey string, owner, repo string, pullNumber int, args map[string]interface{}) (CallToolResult, error) {
+	baseURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/comments", apiBase, owner, repo, pullNumber)
+	params := make([]string, 0)
+
+	for _, key := range []string{"sort", "direction", "since"} {
+		if value, ok := args[key].(string); ok && value != "" {
+			params = append(params, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+
+	// Pagination parameters
+	perPage := 30 // Default value
+	if value, ok := args["per_page"].(float64); ok {
+		if value > 100 {
+			perPage = 100 // Max value
+		} else if value > 0 {
+			perPage = int(value)
+		}
+	}
+	params = append(params, fmt.Sprintf("per_page=%d", perPage))
+
+	page := 1 // Default value
+	if value, ok := args["page"].(float64); ok && value > 0 {
+		page = int(value)
+	}
+	params = append(params, fmt.Sprintf("page=%d", page))
+
+	url := fmt.Sprintf("%s?%s", baseURL, strings.Join(params, "&"))
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Listing review comments: ", url))
+
+	req := newRequest(pdk.MethodGet, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to list review comments: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
+
+func pullRequestCreateReview(apiKey string, owner, repo string, pullNumber int, review Review) (CallToolResult, error) {
+	switch review.Event {
+	case "", "APPROVE", "REQUEST_CHANGES", "COMMENT":
+	default:
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Invalid event %q: expected APPROVE, REQUEST_CHANGES or COMMENT", review.Event)),
+			}},
+		}, nil
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews", apiBase, owner, repo, pullNumber)
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Creating review: ", url))
+
+	req := newRequest(pdk.MethodPost, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+	req.SetHeader("Content-Type", "application/json")
+
+	res, err := json.Marshal(review)
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprint("Failed to marshal review: ", err)),
+			}},
+		}, nil
+	}
+
+	req.SetBody(res)
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to create review: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
+
+func pullRequestSubmitReview(apiKey string, owner, repo string, pullNumber, reviewID int, event, body string) (CallToolResult, error) {
+	event = strings.ToUpper(event)
+	switch event {
+	case "APPROVE", "REQUEST_CHANGES", "COMMENT":
+	default:
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Invalid event %q: expected APPROVE, REQUEST_CHANGES or COMMENT", event)),
+			}},
+		}, nil
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews/%d/events", apiBase, owner, repo, pullNumber, reviewID)
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Submitting review: ", url))
+
+	req := newRequest(pdk.MethodPost, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+	req.SetHeader("Content-Type", "application/json")
+
+	res, err := json.Marshal(map[string]string{
+		"event": event,
+		"body":  body,
+	})
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprint("Failed to marshal review: ", err)),
+			}},
+		}, nil
+	}
+
+	req.SetBody(res)
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to submit review: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
+
+type PullRequestMerge struct {
+	CommitTitle   string `json:"commit_title,omitempty"`
+	CommitMessage string `json:"commit_message,omitempty"`
+	Sha           string `json:"sha,omitempty"`
+	MergeMethod   string `json:"merge_method,omitempty"`
+}
+
+func pullRequestMergeFromArgs(args map[string]interface{}) PullRequestMerge {
+	merge := PullRequestMerge{}
+	merge.CommitTitle, _ = args["commit_title"].(string)
+	merge.CommitMessage, _ = args["commit_message"].(string)
+	merge.Sha, _ = args["sha"].(string)
+	merge.MergeMethod, _ = args["merge_method"].(string)
+	return merge
+}
+
+func pullRequestMerge(apiKey string, owner, repo string, pullNumber int, merge PullRequestMerge) (CallToolResult, error) {
+	switch merge.MergeMethod {
+	case "", "merge", "squash", "rebase":
+	default:
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Invalid merge_method %q: expected merge, squash or rebase", merge.MergeMethod)),
+			}},
+		}, nil
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/merge", apiBase, owner, repo, pullNumber)
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Merging pull request: ", url))
+
+	req := newRequest(pdk.MethodPut, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+	req.SetHeader("Content-Type", "application/json")
+
+	res, err := json.Marshal(merge)
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprint("Failed to marshal merge: ", err)),
+			}},
+		}, nil
+	}
+
+	req.SetBody(res)
+	resp := send(req)
+	switch resp.Status() {
+	case 200:
+		return CallToolResult{
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(string(resp.Body())),
+			}},
+		}, nil
+	case 405:
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Pull request is not mergeable: %s", string(resp.Body()))),
+			}},
+		}, nil
+	case 409:
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Head SHA does not match, the pull request was updated: %s", string(resp.Body()))),
+			}},
+		}, nil
+	default:
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to merge pull request: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+}
+
+type PullRequestUpdate struct {
+	Title               *string `json:"title,omitempty"`
+	Body                *string `json:"body,omitempty"`
+	Base                *string `json:"base,omitempty"`
+	State               *string `json:"state,omitempty"`
+	MaintainerCanModify *bool   `json:"maintainer_can_modify,omitempty"`
+	// Draft can't be changed through the REST API, see pullRequestSetDraft
+	Draft *bool `json:"-"`
+}
+
+func pullRequestUpdateFromArgs(args map[string]interface{}) PullRequestUpdate {
+	update := PullRequestUpdate{}
+	if title, ok := args["title"].(string); ok {
+		update.Title = &title
+	}
+	if body, ok := args["body"].(string); ok {
+		update.Body = &body
+	}
+	if base, ok := args["base"].(string); ok && base != "" {
+		update.Base = &base
+	}
+	if state, ok := args["state"].(string); ok && state != "" {
+		update.State = &state
+	}
+	if canModify, ok := args["maintainer_can_modify"].(bool); ok {
+		update.MaintainerCanModify = &canModify
+	}
+	if draft, ok := args["draft"].(bool); ok {
+		update.Draft = &draft
+	}
+	return update
+}
+
+type PullRequestState struct {
+	NodeID string `json:"node_id"`
+	Draft  bool   `json:"draft"`
+}
+
+func pullRequestUpdate(apiKey string, owner, repo string, pullNumber int, update PullRequestUpdate) (CallToolResult, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", apiBase, owner, repo, pullNumber)
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Updating pull request: ", url))
+
+	req := newRequest(pdk.MethodPatch, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+	req.SetHeader("Content-Type", "application/json")
+
+	res, err := json.Marshal(update)
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprint("Failed to marshal pull request update: ", err)),
+			}},
+		}, nil
+	}
+
+	req.SetBody(res)
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to update pull request: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	body := resp.Body()
+	if update.Draft != nil {
+		var pr PullRequestState
+		if err := json.Unmarshal(body, &pr); err != nil {
+			return CallToolResult{
+				IsError: some(true),
+				Content: []Content{{
+					Type: ContentTypeText,
+					Text: some(fmt.Sprintf("Failed to parse pull request: %s", err)),
+				}},
+			}, nil
+		}
+		if pr.Draft != *update.Draft {
+			if err := pullRequestSetDraft(apiKey, pr.NodeID, *update.Draft); err != nil {
+				return CallToolResult{
+					IsError: some(true),
+					Content: []Content{{
+						Type: ContentTypeText,
+						Text: some(err.Error()),
+					}},
+				}, nil
+			}
+
+			// fetch the pull request again so the result reflects the new draft state
+			req := newRequest(pdk.MethodGet, url)
+			req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+			req.SetHeader("Accept", "application/vnd.github+json")
+			req.SetHeader("User-Agent", "github-mcpx-servlet")
+			resp := send(req)
+			if resp.Status() == 200 {
+				body = resp.Body()
+			}
+		}
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(body)),
+		}},
+	}, nil
+}
+
+// pullRequestSetDraft converts a pull request to a draft or marks it ready
+// for review. Only the GraphQL API supports this.
+func pullRequestSetDraft(apiKey, nodeID string, draft bool) error {
+	mutation := "mutation($id: ID!) { markPullRequestReadyForReview(input: {pullRequestId: $id}) { clientMutationId } }"
+	if draft {
+		mutation = "mutation($id: ID!) { convertPullRequestToDraft(input: {pullRequestId: $id}) { clientMutationId } }"
+	}
+
+	variables := map[string]interface{}{"id": nodeID}
+	if err := graphqlQuery(apiKey, mutation, variables, nil); err != nil {
+		return fmt.Errorf("Failed to change draft state: %w", err)
+	}
+	return nil
+}
+
+func pullRequestUpdateBranch(apiKey string, owner, repo string, pullNumber int, expectedHeadSha string) (CallToolResult, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/update-branch", apiBase, owner, repo, pullNumber)
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Updating pull request branch: ", url))
+
+	req := newRequest(pdk.MethodPut, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+	req.SetHeader("Content-Type", "application/json")
+
+	data := map[string]string{}
+	if expectedHeadSha != "" {
+		data["expected_head_sha"] = expectedHeadSha
+	}
+	res, err := json.Marshal(data)
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprint("Failed to marshal branch update: ", err)),
+			}},
+		}, nil
+	}
+
+	req.SetBody(res)
+	resp := send(req)
+	if resp.Status() != 202 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to update pull request branch: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
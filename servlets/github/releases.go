@@ -0,0 +1,627 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/extism/go-pdk"
+)
+
+var (
+	ListReleasesTool = ToolDescription{
+		Name:        "gh-list-releases",
+		Description: "List the releases of a GitHub repository, including drafts when the token has push access",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":     prop("string", "The owner of the repository"),
+				"repo":      prop("string", "The repository name"),
+				"per_page":  prop("integer", "Number of results per page (max 100)"),
+				"page":      prop("integer", "Page number for pagination"),
+				"max_items": prop("integer", "Follow pagination and return up to this many results across pages (max 1000), with a cursor to continue from when truncated"),
+				"cursor":    prop("string", "The cursor returned by a previous call, to continue listing where it stopped"),
+			},
+			"required": []string{"owner", "repo"},
+		},
+	}
+	GetReleaseTool = ToolDescription{
+		Name:        "gh-get-release",
+		Description: "Get a release of a GitHub repository by id, by tag name, or the latest published release",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":      prop("string", "The owner of the repository"),
+				"repo":       prop("string", "The repository name"),
+				"release_id": prop("integer", "The id of the release"),
+				"tag":        prop("string", "The tag name of the release, used when release_id is not given. Omit both for the latest release"),
+			},
+			"required": []string{"owner", "repo"},
+		},
+	}
+	CreateReleaseTool = ToolDescription{
+		Name:        "gh-create-release",
+		Description: "Create a release in a GitHub repository. The tag is created from target_commitish if it does not exist",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":                    prop("string", "The owner of the repository"),
+				"repo":                     prop("string", "The repository name"),
+				"tag_name":                 prop("string", "The name of the tag"),
+				"target_commitish":         prop("string", "(optional) The branch or commit SHA the tag is created from, defaults to the default branch"),
+				"name":                     prop("string", "The name of the release"),
+				"body":                     prop("string", "The release notes. Combined with the generated notes when generate_release_notes is set"),
+				"draft":                    prop("boolean", "Create an unpublished draft release"),
+				"prerelease":               prop("boolean", "Mark the release as a pre-release"),
+				"generate_release_notes":   prop("boolean", "Generate the name and notes from the merged pull requests since the previous release"),
+				"previous_tag_name":        prop("string", "(optional) The tag to generate release notes from, defaults to the previous release"),
+				"make_latest":              prop("string", "Whether to mark the release as latest: true, false or legacy (default true)"),
+				"discussion_category_name": prop("string", "(optional) Create a discussion in this category linked to the release"),
+			},
+			"required": []string{"owner", "repo", "tag_name"},
+		},
+	}
+	UpdateReleaseTool = ToolDescription{
+		Name:        "gh-update-release",
+		Description: "Update a release in a GitHub repository. Set draft to false to publish a draft release",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":                    prop("string", "The owner of the repository"),
+				"repo":                     prop("string", "The repository name"),
+				"release_id":               prop("integer", "The id of the release"),
+				"tag_name":                 prop("string", "The name of the tag"),
+				"target_commitish":         prop("string", "The branch or commit SHA the tag is created from, if it does not exist yet"),
+				"name":                     prop("string", "The name of the release"),
+				"body":                     prop("string", "The release notes"),
+				"draft":                    prop("boolean", "true to make the release a draft, false to publish it"),
+				"prerelease":               prop("boolean", "Mark the release as a pre-release"),
+				"make_latest":              prop("string", "Whether to mark the release as latest: true, false or legacy"),
+				"discussion_category_name": prop("string", "(optional) Create a discussion in this category linked to the release"),
+			},
+			"required": []string{"owner", "repo", "release_id"},
+		},
+	}
+	DeleteReleaseTool = ToolDescription{
+		Name:        "gh-delete-release",
+		Description: "Delete a release from a GitHub repository. The tag is kept",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":      prop("string", "The owner of the repository"),
+				"repo":       prop("string", "The repository name"),
+				"release_id": prop("integer", "The id of the release"),
+			},
+			"required": []string{"owner", "repo", "release_id"},
+		},
+	}
+	CreateTagTool = ToolDescription{
+		Name:        "gh-create-tag",
+		Description: "Create a tag in a GitHub repository. With a message the tag is annotated, otherwise it is lightweight",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":        prop("string", "The owner of the repository"),
+				"repo":         prop("string", "The repository name"),
+				"tag":          prop("string", "The name of the tag, e.g. v1.0.0"),
+				"sha":          prop("string", "The SHA of the commit to tag"),
+				"message":      prop("string", "(optional) The tag message, creates an annotated tag"),
+				"tagger_name":  prop("string", "(optional) The name of the tagger of an annotated tag, required with tagger_email. Defaults to the authenticated user"),
+				"tagger_email": prop("string", "(optional) The email of the tagger of an annotated tag, required with tagger_name"),
+			},
+			"required": []string{"owner", "repo", "tag", "sha"},
+		},
+	}
+	UploadReleaseAssetTool = ToolDescription{
+		Name:        "gh-upload-release-asset",
+		Description: "Upload a file as an asset of a release, from base64 content or a file path",
+		InputSchema: schema{
+			"type": "object",
+			"properties": props{
+				"owner":        prop("string", "The owner of the repository"),
+				"repo":         prop("string", "The repository name"),
+				"release_id":   prop("integer", "The id of the release"),
+				"name":         prop("string", "The file name of the asset, defaults to the base name of path"),
+				"label":        prop("string", "(optional) A display label for the asset"),
+				"content":      prop("string", "The base64 encoded content of the asset"),
+				"path":         prop("string", "The path of a file to upload instead of content. Must be in a directory the servlet is allowed to read"),
+				"content_type": prop("string", "The media type of the asset (default application/octet-stream)"),
+			},
+			"required": []string{"owner", "repo", "release_id"},
+		},
+	}
+	ReleaseTools = []ToolDescription{
+		ListReleasesTool,
+		GetReleaseTool,
+		CreateReleaseTool,
+		UpdateReleaseTool,
+		DeleteReleaseTool,
+		CreateTagTool,
+		UploadReleaseAssetTool,
+	}
+)
+
+type Release struct {
+	TagName                *string `json:"tag_name,omitempty"`
+	TargetCommitish        *string `json:"target_commitish,omitempty"`
+	Name                   *string `json:"name,omitempty"`
+	Body                   *string `json:"body,omitempty"`
+	Draft                  *bool   `json:"draft,omitempty"`
+	Prerelease             *bool   `json:"prerelease,omitempty"`
+	GenerateReleaseNotes   *bool   `json:"generate_release_notes,omitempty"`
+	MakeLatest             *string `json:"make_latest,omitempty"`
+	DiscussionCategoryName *string `json:"discussion_category_name,omitempty"`
+}
+
+func releaseFromArgs(args map[string]interface{}) Release {
+	release := Release{}
+	for key, field := range map[string]**string{
+		"tag_name":                 &release.TagName,
+		"target_commitish":         &release.TargetCommitish,
+		"name":                     &release.Name,
+		"body":                     &release.Body,
+		"make_latest":              &release.MakeLatest,
+		"discussion_category_name": &release.DiscussionCategoryName,
+	} {
+		if value, ok := args[key].(string); ok && value != "" {
+			*field = &value
+		}
+	}
+	for key, field := range map[string]**bool{
+		"draft":                  &release.Draft,
+		"prerelease":             &release.Prerelease,
+		"generate_release_notes": &release.GenerateReleaseNotes,
+	} {
+		if value, ok := args[key].(bool); ok {
+			*field = &value
+		}
+	}
+	return release
+}
+
+func releasesList(apiKey string, owner, repo string, args map[string]interface{}) (CallToolResult, error) {
+	// Pagination parameters
+	perPage := 30 // Default value
+	if value, ok := args["per_page"].(float64); ok {
+		if value > 100 {
+			perPage = 100 // Max value
+		} else if value > 0 {
+			perPage = int(value)
+		}
+	}
+
+	page := 1 // Default value
+	if value, ok := args["page"].(float64); ok && value > 0 {
+		page = int(value)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=%d&page=%d", apiBase, owner, repo, perPage, page)
+
+	if paginated(args) {
+		page, err := fetchPages[json.RawMessage](apiKey, url, "application/vnd.github+json", args)
+		return pageResult(page, err, "Failed to list releases"), nil
+	}
+
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Listing releases: ", url))
+
+	req := newRequest(pdk.MethodGet, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to list releases: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
+
+func releaseGet(apiKey string, owner, repo string, releaseID int, tag string) (CallToolResult, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/latest", apiBase, owner, repo)
+	if releaseID > 0 {
+		url = fmt.Sprintf("%s/repos/%s/%s/releases/%d", apiBase, owner, repo, releaseID)
+	} else if tag != "" {
+		url = fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", apiBase, owner, repo, tag)
+	}
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Getting release: ", url))
+
+	req := newRequest(pdk.MethodGet, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to get release: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
+
+// releaseGenerateNotes asks GitHub for the release notes it would generate
+// for a tag, so they can be combined with a hand-written body.
+func releaseGenerateNotes(apiKey string, owner, repo string, release Release, previousTag string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/generate-notes", apiBase, owner, repo)
+	req := newRequest(pdk.MethodPost, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+	req.SetHeader("Content-Type", "application/json")
+
+	data := map[string]string{"tag_name": *release.TagName}
+	if release.TargetCommitish != nil {
+		data["target_commitish"] = *release.TargetCommitish
+	}
+	if previousTag != "" {
+		data["previous_tag_name"] = previousTag
+	}
+	res, _ := json.Marshal(data)
+	req.SetBody(res)
+
+	resp := send(req)
+	if resp.Status() != 200 {
+		return "", fmt.Errorf("Failed to generate release notes: %d %s", resp.Status(), string(resp.Body()))
+	}
+
+	var notes struct {
+		Body string `json:"body"`
+	}
+	if err := json.Unmarshal(resp.Body(), &notes); err != nil {
+		return "", fmt.Errorf("Failed to parse release notes: %w", err)
+	}
+	return notes.Body, nil
+}
+
+func releaseCreate(apiKey string, owner, repo string, release Release, previousTag string) (CallToolResult, error) {
+	if release.TagName == nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some("tag_name is required"),
+			}},
+		}, nil
+	}
+
+	// generate_release_notes can't pick the previous tag, so generate the
+	// notes separately when one is given
+	if previousTag != "" && release.GenerateReleaseNotes != nil && *release.GenerateReleaseNotes {
+		notes, err := releaseGenerateNotes(apiKey, owner, repo, release, previousTag)
+		if err != nil {
+			return CallToolResult{
+				IsError: some(true),
+				Content: []Content{{
+					Type: ContentTypeText,
+					Text: some(err.Error()),
+				}},
+			}, nil
+		}
+		if release.Body != nil {
+			notes = *release.Body + "\n\n" + notes
+		}
+		release.Body = &notes
+		release.GenerateReleaseNotes = nil
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/releases", apiBase, owner, repo)
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Creating release: ", url))
+
+	req := newRequest(pdk.MethodPost, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+	req.SetHeader("Content-Type", "application/json")
+
+	res, err := json.Marshal(release)
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprint("Failed to marshal release: ", err)),
+			}},
+		}, nil
+	}
+
+	req.SetBody(res)
+	resp := send(req)
+	if resp.Status() != 201 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to create release: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
+
+func releaseUpdate(apiKey string, owner, repo string, releaseID int, release Release) (CallToolResult, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/%d", apiBase, owner, repo, releaseID)
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Updating release: ", url))
+
+	req := newRequest(pdk.MethodPatch, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+	req.SetHeader("Content-Type", "application/json")
+
+	res, err := json.Marshal(release)
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprint("Failed to marshal release: ", err)),
+			}},
+		}, nil
+	}
+
+	req.SetBody(res)
+	resp := send(req)
+	if resp.Status() != 200 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to update release: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}, nil
+}
+
+func releaseDelete(apiKey string, owner, repo string, releaseID int) (CallToolResult, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/%d", apiBase, owner, repo, releaseID)
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Deleting release: ", url))
+
+	req := newRequest(pdk.MethodDelete, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+	resp := send(req)
+	if resp.Status() != 204 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to delete release: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}, nil
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(fmt.Sprintf("Deleted release %d", releaseID)),
+		}},
+	}, nil
+}
+
+type TagObject struct {
+	Tag     string  `json:"tag"`
+	Message string  `json:"message"`
+	Object  string  `json:"object"`
+	Type    string  `json:"type"`
+	Tagger  *Tagger `json:"tagger,omitempty"`
+}
+
+// Tagger is the tagger of an annotated tag. GitHub uses the current time
+// when the date is omitted, and rejects an empty one.
+type Tagger struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Date  string `json:"date,omitempty"`
+}
+
+// tagCreate creates refs/tags/{tag}. An annotated tag first needs a tag
+// object that the ref then points to instead of the commit.
+func tagCreate(apiKey string, owner, repo, tag, sha, message, taggerName, taggerEmail string) CallToolResult {
+	if (taggerName == "") != (taggerEmail == "") {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some("tagger_name and tagger_email must be provided together"),
+			}},
+		}
+	}
+
+	target := sha
+	if message != "" {
+		tagObject := TagObject{Tag: tag, Message: message, Object: sha, Type: "commit"}
+		if taggerName != "" {
+			tagObject.Tagger = &Tagger{Name: taggerName, Email: taggerEmail}
+		}
+
+		url := fmt.Sprintf("%s/repos/%s/%s/git/tags", apiBase, owner, repo)
+		req := newRequest(pdk.MethodPost, url)
+		req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+		req.SetHeader("Accept", "application/vnd.github+json")
+		req.SetHeader("User-Agent", "github-mcpx-servlet")
+		req.SetHeader("Content-Type", "application/json")
+
+		res, _ := json.Marshal(tagObject)
+		req.SetBody(res)
+		resp := send(req)
+		if resp.Status() != 201 {
+			return CallToolResult{
+				IsError: some(true),
+				Content: []Content{{
+					Type: ContentTypeText,
+					Text: some(fmt.Sprintf("Failed to create tag object: %d %s", resp.Status(), string(resp.Body()))),
+				}},
+			}
+		}
+
+		var created struct {
+			Sha string `json:"sha"`
+		}
+		err := json.Unmarshal(resp.Body(), &created)
+		if err == nil && created.Sha == "" {
+			err = errors.New("no sha in response")
+		}
+		if err != nil {
+			return CallToolResult{
+				IsError: some(true),
+				Content: []Content{{
+					Type: ContentTypeText,
+					Text: some(fmt.Sprintf("Failed to parse tag object: %s", err)),
+				}},
+			}
+		}
+		target = created.Sha
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/git/refs", apiBase, owner, repo)
+	req := newRequest(pdk.MethodPost, url)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+	req.SetHeader("Content-Type", "application/json")
+
+	res, _ := json.Marshal(map[string]string{
+		"ref": fmt.Sprintf("refs/tags/%s", tag),
+		"sha": target,
+	})
+	req.SetBody(res)
+	resp := send(req)
+	if resp.Status() != 201 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to create tag: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}
+}
+
+type ReleaseAsset struct {
+	Name        string
+	Label       string
+	ContentType string
+	Content     []byte
+}
+
+func releaseAssetFromArgs(args map[string]interface{}) (ReleaseAsset, error) {
+	asset := ReleaseAsset{ContentType: "application/octet-stream"}
+	asset.Name, _ = args["name"].(string)
+	asset.Label, _ = args["label"].(string)
+	if contentType, ok := args["content_type"].(string); ok && contentType != "" {
+		asset.ContentType = contentType
+	}
+
+	content, _ := args["content"].(string)
+	path, _ := args["path"].(string)
+	switch {
+	case content != "" && path != "":
+		return asset, errors.New("Only one of content or path can be given")
+	case content != "":
+		data, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return asset, fmt.Errorf("Invalid base64 content: %w", err)
+		}
+		asset.Content = data
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return asset, fmt.Errorf("Failed to read %s: %w", path, err)
+		}
+		asset.Content = data
+		if asset.Name == "" {
+			asset.Name = filepath.Base(path)
+		}
+	default:
+		return asset, errors.New("Either content or path is required")
+	}
+
+	if asset.Name == "" {
+		return asset, errors.New("name is required when uploading content")
+	}
+	return asset, nil
+}
+
+func releaseUploadAsset(apiKey string, owner, repo string, releaseID int, asset ReleaseAsset) CallToolResult {
+	params := url.Values{}
+	params.Set("name", asset.Name)
+	if asset.Label != "" {
+		params.Set("label", asset.Label)
+	}
+
+	u := fmt.Sprintf("%s/repos/%s/%s/releases/%d/assets?%s", uploadsBase, owner, repo, releaseID, params.Encode())
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Uploading release asset: ", u))
+
+	req := newRequest(pdk.MethodPost, u)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+	req.SetHeader("Content-Type", asset.ContentType)
+
+	req.SetBody(asset.Content)
+	resp := send(req)
+	if resp.Status() != 201 {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to upload release asset: %d %s", resp.Status(), string(resp.Body()))),
+			}},
+		}
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(resp.Body())),
+		}},
+	}
+}
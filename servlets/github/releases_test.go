@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTagCreate(t *testing.T) {
+	t.Run("annotated tag with a tagger", func(t *testing.T) {
+		api := useFakeAPI(t,
+			fakeResponse{Status: 201, Body: `{"sha":"c3d0be41ecbe669545ee3e94d31ed9a4bc91ee3c"}`},
+			fakeResponse{Status: 201, Body: `{"ref":"refs/tags/v1.0.0"}`},
+		)
+		prevAPIBase := apiBase
+		apiBase = api.url
+		defer func() { apiBase = prevAPIBase }()
+
+		result := tagCreate("ghp_test", "octocat", "hello-world", "v1.0.0", "7638417db6d59f3c431d3e1f261cc637155684cd", "Release 1.0", "Mona Lisa", "mona@github.com")
+		if result.IsError != nil && *result.IsError {
+			t.Fatalf("tagCreate() = %s", *result.Content[0].Text)
+		}
+
+		requests := api.requests()
+		if len(requests) != 2 {
+			t.Fatalf("got %d requests, want 2", len(requests))
+		}
+		if requests[0].Method != "POST" || requests[0].Path != "/repos/octocat/hello-world/git/tags" {
+			t.Errorf("first request = %s %s, want POST /repos/octocat/hello-world/git/tags", requests[0].Method, requests[0].Path)
+		}
+		var tagObject map[string]interface{}
+		if err := json.Unmarshal([]byte(requests[0].Body), &tagObject); err != nil {
+			t.Fatalf("tag object body %q: %s", requests[0].Body, err)
+		}
+		if want := map[string]interface{}{"name": "Mona Lisa", "email": "mona@github.com"}; !reflect.DeepEqual(tagObject["tagger"], want) {
+			t.Errorf("tagger = %v, want %v without a date", tagObject["tagger"], want)
+		}
+
+		var ref map[string]string
+		if err := json.Unmarshal([]byte(requests[1].Body), &ref); err != nil {
+			t.Fatalf("ref body %q: %s", requests[1].Body, err)
+		}
+		if want := map[string]string{"ref": "refs/tags/v1.0.0", "sha": "c3d0be41ecbe669545ee3e94d31ed9a4bc91ee3c"}; requests[1].Path != "/repos/octocat/hello-world/git/refs" || !reflect.DeepEqual(ref, want) {
+			t.Errorf("second request = %s %v, want /repos/octocat/hello-world/git/refs %v", requests[1].Path, ref, want)
+		}
+	})
+
+	t.Run("tagger with only a name or an email", func(t *testing.T) {
+		api := useFakeAPI(t)
+		prevAPIBase := apiBase
+		apiBase = api.url
+		defer func() { apiBase = prevAPIBase }()
+
+		for _, tagger := range [][2]string{{"Mona Lisa", ""}, {"", "mona@github.com"}} {
+			result := tagCreate("ghp_test", "octocat", "hello-world", "v1.0.0", "7638417db6d59f3c431d3e1f261cc637155684cd", "Release 1.0", tagger[0], tagger[1])
+			if result.IsError == nil || !*result.IsError {
+				t.Errorf("tagCreate() with tagger %q <%s> succeeded, want an error", tagger[0], tagger[1])
+			}
+		}
+		if n := len(api.requests()); n != 0 {
+			t.Errorf("got %d requests, want none", n)
+		}
+	})
+}
@@ -15,10 +15,12 @@ var (
 		InputSchema: schema{
 			"type": "object",
 			"properties": props{
-				"owner":    prop("string", "The owner of the repository"),
-				"repo":     prop("string", "The repository name"),
-				"per_page": prop("integer", "Number of results per page (max 100)"),
-				"page":     prop("integer", "Page number for pagination"),
+				"owner":     prop("string", "The owner of the repository"),
+				"repo":      prop("string", "The repository name"),
+				"per_page":  prop("integer", "Number of results per page (max 100)"),
+				"page":      prop("integer", "Page number for pagination"),
+				"max_items": prop("integer", "Follow pagination and return up to this many results across pages (max 1000), with a cursor to continue from when truncated"),
+				"cursor":    prop("string", "The cursor returned by a previous call, to continue listing where it stopped"),
 			},
 			"required": []string{"owner", "repo"},
 		},
@@ -29,10 +31,12 @@ var (
 		InputSchema: schema{
 			"type": "object",
 			"properties": props{
-				"owner":    prop("string", "The owner of the repository"),
-				"repo":     prop("string", "The repository name"),
-				"per_page": prop("integer", "Number of results per page (max 100)"),
-				"page":     prop("integer", "Page number for pagination"),
+				"owner":     prop("string", "The owner of the repository"),
+				"repo":      prop("string", "The repository name"),
+				"per_page":  prop("integer", "Number of results per page (max 100)"),
+				"page":      prop("integer", "Page number for pagination"),
+				"max_items": prop("integer", "Follow pagination and return up to this many results across pages (max 1000), with a cursor to continue from when truncated"),
+				"cursor":    prop("string", "The cursor returned by a previous call, to continue listing where it stopped"),
 			},
 			"required": []string{"owner", "repo"},
 		},
@@ -61,6 +65,8 @@ var (
 				"direction": prop("string", "The sort direction (asc or desc)"),
 				"per_page":  prop("integer", "Number of results per page (max 100)"),
 				"page":      prop("integer", "Page number for pagination"),
+				"max_items": prop("integer", "Follow pagination and return up to this many results across pages (max 1000), with a cursor to continue from when truncated"),
+				"cursor":    prop("string", "The cursor returned by a previous call, to continue listing where it stopped"),
 			},
 			"required": []string{"username"},
 		},
@@ -96,7 +102,7 @@ type Contributor struct {
 }
 
 func reposGetContributors(apiKey string, owner, repo string, args map[string]interface{}) (CallToolResult, error) {
-	baseURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/contributors", owner, repo)
+	baseURL := fmt.Sprintf("%s/repos/%s/%s/contributors", apiBase, owner, repo)
 	params := make([]string, 0)
 
 	// Pagination parameters
@@ -122,15 +128,20 @@ func reposGetContributors(apiKey string, owner, repo string, args map[string]int
 		url = fmt.Sprintf("%s?%s", baseURL, strings.Join(params, "&"))
 	}
 
+	if paginated(args) {
+		page, err := fetchPages[Contributor](apiKey, url, "application/vnd.github+json", args)
+		return pageResult(page, err, "Failed to fetch contributors"), nil
+	}
+
 	pdk.Log(pdk.LogDebug, fmt.Sprint("Fetching contributors: ", url))
 
 	// Make request
-	req := pdk.NewHTTPRequest(pdk.MethodGet, url)
+	req := newRequest(pdk.MethodGet, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 200 {
 		return CallToolResult{
 			IsError: some(true),
@@ -200,7 +211,7 @@ type Collaborator struct {
 }
 
 func reposGetCollaborators(apiKey string, owner, repo string, args map[string]interface{}) (CallToolResult, error) {
-	baseURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/collaborators", owner, repo)
+	baseURL := fmt.Sprintf("%s/repos/%s/%s/collaborators", apiBase, owner, repo)
 	params := make([]string, 0)
 
 	// Pagination parameters
@@ -226,15 +237,20 @@ func reposGetCollaborators(apiKey string, owner, repo string, args map[string]in
 		url = fmt.Sprintf("%s?%s", baseURL, strings.Join(params, "&"))
 	}
 
+	if paginated(args) {
+		page, err := fetchPages[Collaborator](apiKey, url, "application/vnd.github+json", args)
+		return pageResult(page, err, "Failed to fetch collaborators"), nil
+	}
+
 	pdk.Log(pdk.LogDebug, fmt.Sprint("Fetching collaborators: ", url))
 
 	// Make request
-	req := pdk.NewHTTPRequest(pdk.MethodGet, url)
+	req := newRequest(pdk.MethodGet, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 200 {
 		return CallToolResult{
 			IsError: some(true),
@@ -297,15 +313,15 @@ type RepositoryDetails struct {
 }
 
 func reposGetDetails(apiKey string, owner, repo string) (CallToolResult, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s", apiBase, owner, repo)
 	pdk.Log(pdk.LogDebug, fmt.Sprint("Fetching repository details: ", url))
 
-	req := pdk.NewHTTPRequest(pdk.MethodGet, url)
+	req := newRequest(pdk.MethodGet, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 200 {
 		return CallToolResult{
 			IsError: some(true),
@@ -347,7 +363,7 @@ func reposGetDetails(apiKey string, owner, repo string) (CallToolResult, error)
 }
 
 func reposList(apiKey string, username string, args map[string]interface{}) (CallToolResult, error) {
-	baseURL := fmt.Sprintf("https://api.github.com/users/%s/repos", username)
+	baseURL := fmt.Sprintf("%s/users/%s/repos", apiBase, username)
 	params := make([]string, 0)
 
 	// Optional parameters
@@ -384,15 +400,20 @@ func reposList(apiKey string, username string, args map[string]interface{}) (Cal
 		url = fmt.Sprintf("%s?%s", baseURL, strings.Join(params, "&"))
 	}
 
+	if paginated(args) {
+		page, err := fetchPages[json.RawMessage](apiKey, url, "application/vnd.github+json", args)
+		return pageResult(page, err, "Failed to fetch repositories"), nil
+	}
+
 	pdk.Log(pdk.LogDebug, fmt.Sprint("Fetching repositories: ", url))
 
 	// Make request
-	req := pdk.NewHTTPRequest(pdk.MethodGet, url)
+	req := newRequest(pdk.MethodGet, url)
 	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
 	req.SetHeader("Accept", "application/vnd.github+json")
 	req.SetHeader("User-Agent", "github-mcpx-servlet")
 
-	resp := req.Send()
+	resp := send(req)
 	if resp.Status() != 200 {
 		return CallToolResult{
 			IsError: some(true),
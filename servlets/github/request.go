@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/extism/go-pdk"
+)
+
+const (
+	maxRetries = 3
+	// longest single wait before giving up on a retry
+	maxRetryDelay = 60 * time.Second
+)
+
+// RateLimit is the rate limit state GitHub reported with the last response.
+type RateLimit struct {
+	Limit      int    `json:"limit"`
+	Remaining  int    `json:"remaining"`
+	Used       int    `json:"used"`
+	Resource   string `json:"resource,omitempty"`
+	Reset      string `json:"reset,omitempty"`
+	RetryAfter int    `json:"retry_after,omitempty"`
+}
+
+// lastRateLimit is updated by send and reported by Call.
+var lastRateLimit *RateLimit
+
+// request is a pdk.HTTPRequest that remembers its method and Authorization
+// header, which send needs to decide whether it may be retried and to
+// re-authenticate it.
+type request struct {
+	*pdk.HTTPRequest
+	method        pdk.HTTPMethod
+	authorization string
+}
+
+func newRequest(method pdk.HTTPMethod, url string) *request {
+	return &request{
+		HTTPRequest: pdk.NewHTTPRequest(method, url),
+		method:      method,
+	}
+}
+
+func (r *request) SetHeader(key, value string) *request {
+	if key == "Authorization" {
+		r.authorization = value
+	}
+	r.HTTPRequest.SetHeader(key, value)
+	return r
+}
+
+// sleep waits before a retry. Tests replace it to record the delays.
+var sleep = time.Sleep
+
+// header returns the value of a response header. Hosts may normalize header
+// names to lower case.
+func header(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseRateLimit reads the X-RateLimit-* and Retry-After headers, returning
+// nil when the response has none.
+func parseRateLimit(headers map[string]string) *RateLimit {
+	remaining := header(headers, "X-RateLimit-Remaining")
+	retryAfter := header(headers, "Retry-After")
+	if remaining == "" && retryAfter == "" {
+		return nil
+	}
+
+	rl := &RateLimit{Resource: header(headers, "X-RateLimit-Resource")}
+	rl.Limit, _ = strconv.Atoi(header(headers, "X-RateLimit-Limit"))
+	rl.Remaining, _ = strconv.Atoi(remaining)
+	rl.Used, _ = strconv.Atoi(header(headers, "X-RateLimit-Used"))
+	rl.RetryAfter, _ = strconv.Atoi(retryAfter)
+	if reset, err := strconv.ParseInt(header(headers, "X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(reset, 0).UTC().Format(time.RFC3339)
+	}
+	return rl
+}
+
+// idempotent reports whether repeating a request has the same effect as
+// sending it once.
+func idempotent(method pdk.HTTPMethod) bool {
+	switch method {
+	case pdk.MethodGet, pdk.MethodHead, pdk.MethodPut, pdk.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryDelay decides whether a response should be retried and how long to
+// wait first. Secondary rate limits and server errors are retried with
+// exponential backoff, honoring Retry-After. An exhausted primary rate limit
+// is not retried since it only resets after up to an hour. Server errors are
+// only retried for idempotent methods: GitHub may have applied a POST or
+// PATCH before failing, and repeating it would create duplicates.
+func retryDelay(method pdk.HTTPMethod, status uint16, headers map[string]string, body []byte, attempt int) (time.Duration, bool) {
+	if attempt >= maxRetries {
+		return 0, false
+	}
+	backoff := time.Duration(1<<attempt) * time.Second
+
+	switch {
+	case status == 403 || status == 429:
+		if header(headers, "X-RateLimit-Remaining") == "0" {
+			return 0, false
+		}
+		retryAfter := header(headers, "Retry-After")
+		secondary := retryAfter != "" || status == 429 ||
+			bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit"))
+		if !secondary {
+			return 0, false
+		}
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			backoff = time.Duration(seconds) * time.Second
+		}
+	case status >= 500 && status != 501 && idempotent(method):
+	default:
+		return 0, false
+	}
+
+	if backoff > maxRetryDelay {
+		return 0, false
+	}
+	return backoff, true
+}
+
+// send sends req, retrying secondary rate limits, and server errors of
+// idempotent requests, with backoff. A request rejected with 401 is sent
+// once more when a new installation token can be created. send records the
+// rate limit state of the final response and adds the configured
+// api-version header.
+func send(req *request) pdk.HTTPResponse {
+	if apiVersion != "" {
+		req.SetHeader("X-GitHub-Api-Version", apiVersion)
+	}
+	reauthorized := false
+	for attempt := 0; ; attempt++ {
+		resp := req.Send()
+		headers := resp.Headers()
+		if rl := parseRateLimit(headers); rl != nil {
+			lastRateLimit = rl
+		}
+
+		if resp.Status() == 401 && !reauthorized {
+			// the installation token was revoked or expired early
+			reauthorized = true
+			if token, ok := refreshToken(req); ok {
+				req.SetHeader("Authorization", fmt.Sprint("token ", token))
+				// sending it again with the new token is not a retry
+				attempt--
+				continue
+			}
+		}
+
+		delay, retry := retryDelay(req.method, resp.Status(), headers, resp.Body(), attempt)
+		if !retry {
+			return resp
+		}
+		pdk.Log(pdk.LogWarn, fmt.Sprintf("%s request failed with status %d, retrying in %s", req.method, resp.Status(), delay))
+		sleep(delay)
+	}
+}
+
+// withRateLimit adds the last rate limit state to a result when the call
+// failed or the remaining quota is below 10%, so the caller can slow down.
+func withRateLimit(result CallToolResult) CallToolResult {
+	rl := lastRateLimit
+	if rl == nil {
+		return result
+	}
+	failed := result.IsError != nil && *result.IsError
+	low := rl.Limit > 0 && rl.Remaining*10 < rl.Limit
+	if !failed && !low {
+		return result
+	}
+
+	res, err := json.Marshal(map[string]*RateLimit{"rate_limit": rl})
+	if err != nil {
+		return result
+	}
+	result.Content = append(result.Content, Content{
+		Type: ContentTypeText,
+		Text: some(string(res)),
+	})
+	return result
+}
+
+// graphqlQuery posts a GraphQL query and decodes its data into result,
+// which may be nil. GraphQL reports errors with a 200 status, so the
+// response errors are checked as well.
+func graphqlQuery(apiKey, query string, variables map[string]interface{}, result any) error {
+	req := newRequest(pdk.MethodPost, graphqlURL)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", "application/vnd.github+json")
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+	req.SetHeader("Content-Type", "application/json")
+
+	res, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to marshal query: %w", err)
+	}
+
+	req.SetBody(res)
+	resp := send(req)
+	if resp.Status() != 200 {
+		return fmt.Errorf("%d %s", resp.Status(), string(resp.Body()))
+	}
+
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(resp.Body(), &response); err != nil {
+		return fmt.Errorf("Failed to parse response: %w", err)
+	}
+	if len(response.Errors) > 0 {
+		return errors.New(response.Errors[0].Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Data, result)
+}
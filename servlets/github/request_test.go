@@ -0,0 +1,366 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/extism/go-pdk"
+)
+
+// fakeResponse and fakeRequest are what the fake API run by test/extism-exec
+// replays and records.
+type fakeResponse struct {
+	Status  uint16            `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+type fakeRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+type fakeAPI struct {
+	t      *testing.T
+	url    string
+	delays []time.Duration
+}
+
+// useFakeAPI queues responses on the fake API, one per request it receives,
+// and records the delays send waits between attempts instead of sleeping.
+func useFakeAPI(t *testing.T, responses ...fakeResponse) *fakeAPI {
+	url := os.Getenv("FAKE_API_URL")
+	if url == "" {
+		t.Skip("FAKE_API_URL is not set, run the tests with just unit-test")
+	}
+	body, err := json.Marshal(responses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := pdk.NewHTTPRequest(pdk.MethodPost, url+"/_fake/responses")
+	req.SetBody(body)
+	if resp := req.Send(); resp.Status() != 204 {
+		t.Fatalf("queueing responses: %d %s", resp.Status(), resp.Body())
+	}
+
+	api := &fakeAPI{t: t, url: url}
+	prevSleep := sleep
+	sleep = func(d time.Duration) {
+		api.delays = append(api.delays, d)
+	}
+	lastRateLimit = nil
+	t.Cleanup(func() {
+		sleep = prevSleep
+		lastRateLimit = nil
+	})
+	return api
+}
+
+// requests returns the requests the fake API received.
+func (api *fakeAPI) requests() []fakeRequest {
+	resp := pdk.NewHTTPRequest(pdk.MethodGet, api.url+"/_fake/requests").Send()
+	var requests []fakeRequest
+	if err := json.Unmarshal(resp.Body(), &requests); err != nil {
+		api.t.Fatalf("reading requests: %s", err)
+	}
+	return requests
+}
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    *RateLimit
+	}{
+		{
+			name:    "no rate limit headers",
+			headers: map[string]string{"Content-Type": "application/json"},
+		},
+		{
+			name: "primary rate limit",
+			headers: map[string]string{
+				"x-ratelimit-limit":     "5000",
+				"x-ratelimit-remaining": "4990",
+				"x-ratelimit-used":      "10",
+				"x-ratelimit-resource":  "core",
+				"x-ratelimit-reset":     "1700000000",
+			},
+			want: &RateLimit{Limit: 5000, Remaining: 4990, Used: 10, Resource: "core", Reset: "2023-11-14T22:13:20Z"},
+		},
+		{
+			name:    "retry after only",
+			headers: map[string]string{"Retry-After": "30"},
+			want:    &RateLimit{RetryAfter: 30},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRateLimit(tt.headers)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRateLimit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    pdk.HTTPMethod
+		status    uint16
+		headers   map[string]string
+		body      string
+		attempt   int
+		wantDelay time.Duration
+		wantRetry bool
+	}{
+		{name: "success", method: pdk.MethodGet, status: 200},
+		{name: "not found", method: pdk.MethodGet, status: 404},
+		{name: "forbidden without rate limit", method: pdk.MethodGet, status: 403, body: `{"message":"Resource not accessible by integration"}`},
+		{name: "server error", method: pdk.MethodGet, status: 502, wantDelay: time.Second, wantRetry: true},
+		{name: "server error backs off", method: pdk.MethodGet, status: 503, attempt: 2, wantDelay: 4 * time.Second, wantRetry: true},
+		{name: "server error out of retries", method: pdk.MethodGet, status: 503, attempt: 3},
+		{name: "not implemented", method: pdk.MethodGet, status: 501},
+		{name: "server error on put", method: pdk.MethodPut, status: 500, wantDelay: time.Second, wantRetry: true},
+		{name: "server error on delete", method: pdk.MethodDelete, status: 500, wantDelay: time.Second, wantRetry: true},
+		{name: "server error on post", method: pdk.MethodPost, status: 502},
+		{name: "server error on patch", method: pdk.MethodPatch, status: 500},
+		{
+			name:    "primary rate limit",
+			method:  pdk.MethodGet,
+			status:  403,
+			headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1700000000"},
+			body:    `{"message":"API rate limit exceeded"}`,
+		},
+		{
+			name:    "primary rate limit with 429",
+			method:  pdk.MethodGet,
+			status:  429,
+			headers: map[string]string{"x-ratelimit-remaining": "0"},
+		},
+		{
+			name:      "secondary rate limit message",
+			method:    pdk.MethodPost,
+			status:    403,
+			headers:   map[string]string{"X-RateLimit-Remaining": "4000"},
+			body:      `{"message":"You have exceeded a secondary rate limit"}`,
+			attempt:   1,
+			wantDelay: 2 * time.Second,
+			wantRetry: true,
+		},
+		{name: "too many requests", method: pdk.MethodPost, status: 429, wantDelay: time.Second, wantRetry: true},
+		{
+			name:      "retry after",
+			method:    pdk.MethodGet,
+			status:    403,
+			headers:   map[string]string{"Retry-After": "30"},
+			wantDelay: 30 * time.Second,
+			wantRetry: true,
+		},
+		{
+			name:      "retry after at the cap",
+			method:    pdk.MethodGet,
+			status:    429,
+			headers:   map[string]string{"retry-after": "60"},
+			wantDelay: time.Minute,
+			wantRetry: true,
+		},
+		{
+			name:    "retry after over the cap",
+			method:  pdk.MethodGet,
+			status:  429,
+			headers: map[string]string{"Retry-After": "120"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := retryDelay(tt.method, tt.status, tt.headers, []byte(tt.body), tt.attempt)
+			if delay != tt.wantDelay || retry != tt.wantRetry {
+				t.Errorf("retryDelay() = %s, %t, want %s, %t", delay, retry, tt.wantDelay, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	ok := CallToolResult{Content: []Content{{Type: ContentTypeText, Text: some("{}")}}}
+	failed := CallToolResult{IsError: some(true), Content: []Content{{Type: ContentTypeText, Text: some("Failed")}}}
+
+	tests := []struct {
+		name      string
+		rateLimit *RateLimit
+		result    CallToolResult
+		want      string
+	}{
+		{name: "no rate limit", result: failed},
+		{name: "plenty left", rateLimit: &RateLimit{Limit: 5000, Remaining: 4000}, result: ok},
+		{
+			name:      "below 10 percent",
+			rateLimit: &RateLimit{Limit: 5000, Remaining: 499, Used: 4501, Resource: "core"},
+			result:    ok,
+			want:      `{"rate_limit":{"limit":5000,"remaining":499,"used":4501,"resource":"core"}}`,
+		},
+		{
+			name:      "failed call",
+			rateLimit: &RateLimit{Limit: 5000, Remaining: 4000, RetryAfter: 120},
+			result:    failed,
+			want:      `{"rate_limit":{"limit":5000,"remaining":4000,"used":0,"retry_after":120}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lastRateLimit = tt.rateLimit
+			defer func() { lastRateLimit = nil }()
+
+			got := withRateLimit(tt.result)
+			if tt.want == "" {
+				if len(got.Content) != len(tt.result.Content) {
+					t.Fatalf("withRateLimit() added %d content blocks, want none", len(got.Content)-len(tt.result.Content))
+				}
+				return
+			}
+			if len(got.Content) != len(tt.result.Content)+1 {
+				t.Fatalf("withRateLimit() returned %d content blocks, want %d", len(got.Content), len(tt.result.Content)+1)
+			}
+			if text := *got.Content[len(got.Content)-1].Text; text != tt.want {
+				t.Errorf("rate limit block = %s, want %s", text, tt.want)
+			}
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	rateLimited := map[string]string{
+		"X-RateLimit-Limit":     "5000",
+		"X-RateLimit-Remaining": "4999",
+		"X-RateLimit-Used":      "1",
+	}
+
+	t.Run("retries server errors and secondary rate limits", func(t *testing.T) {
+		api := useFakeAPI(t,
+			fakeResponse{Status: 502},
+			fakeResponse{Status: 403, Headers: map[string]string{"Retry-After": "3"}, Body: `{"message":"You have exceeded a secondary rate limit"}`},
+			fakeResponse{Status: 200, Headers: rateLimited, Body: `[]`},
+		)
+		req := newRequest(pdk.MethodGet, api.url+"/repos/octocat/hello-world/issues?state=open")
+		req.SetHeader("Authorization", "token ghp_test")
+		req.SetHeader("Accept", "application/vnd.github+json")
+		resp := send(req)
+		if resp.Status() != 200 || string(resp.Body()) != `[]` {
+			t.Errorf("send() = %d %s, want 200 []", resp.Status(), resp.Body())
+		}
+		if want := []time.Duration{time.Second, 3 * time.Second}; !reflect.DeepEqual(api.delays, want) {
+			t.Errorf("delays = %v, want %v", api.delays, want)
+		}
+		if lastRateLimit == nil || lastRateLimit.Remaining != 4999 {
+			t.Errorf("lastRateLimit = %+v, want remaining 4999", lastRateLimit)
+		}
+
+		requests := api.requests()
+		if len(requests) != 3 {
+			t.Fatalf("got %d requests, want 3", len(requests))
+		}
+		for _, r := range requests {
+			if r.Method != "GET" || r.Path != "/repos/octocat/hello-world/issues?state=open" ||
+				r.Headers["Authorization"] != "token ghp_test" || r.Headers["Accept"] != "application/vnd.github+json" {
+				t.Errorf("request = %+v, want GET /repos/octocat/hello-world/issues?state=open with the token and Accept headers", r)
+			}
+		}
+	})
+
+	t.Run("gives up after the last retry", func(t *testing.T) {
+		api := useFakeAPI(t,
+			fakeResponse{Status: 503},
+			fakeResponse{Status: 503},
+			fakeResponse{Status: 503},
+			fakeResponse{Status: 503},
+		)
+		resp := send(newRequest(pdk.MethodGet, api.url+"/user/repos"))
+		if resp.Status() != 503 {
+			t.Errorf("send() = %d, want 503", resp.Status())
+		}
+		if want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}; !reflect.DeepEqual(api.delays, want) {
+			t.Errorf("delays = %v, want %v", api.delays, want)
+		}
+		if n := len(api.requests()); n != 4 {
+			t.Errorf("got %d requests, want 4", n)
+		}
+	})
+
+	t.Run("does not repeat a failed post", func(t *testing.T) {
+		api := useFakeAPI(t, fakeResponse{Status: 502})
+		resp := send(newRequest(pdk.MethodPost, api.url+"/repos/octocat/hello-world/issues"))
+		if n := len(api.requests()); resp.Status() != 502 || n != 1 {
+			t.Errorf("send() = %d after %d requests, want 502 after 1", resp.Status(), n)
+		}
+	})
+
+	t.Run("retries a post on a secondary rate limit", func(t *testing.T) {
+		api := useFakeAPI(t,
+			fakeResponse{Status: 429, Headers: map[string]string{"Retry-After": "1"}},
+			fakeResponse{Status: 201, Body: `{"number":1}`},
+		)
+		req := newRequest(pdk.MethodPost, api.url+"/repos/octocat/hello-world/issues")
+		req.SetBody([]byte(`{"title":"Found a bug"}`))
+		resp := send(req)
+		if resp.Status() != 201 {
+			t.Errorf("send() = %d, want 201", resp.Status())
+		}
+
+		requests := api.requests()
+		if len(requests) != 2 {
+			t.Fatalf("got %d requests, want 2", len(requests))
+		}
+		for _, r := range requests {
+			if r.Method != "POST" || r.Body != `{"title":"Found a bug"}` {
+				t.Errorf("request = %s %s, want POST {\"title\":\"Found a bug\"}", r.Method, r.Body)
+			}
+		}
+	})
+
+	t.Run("returns a 401 when there is no installation token to refresh", func(t *testing.T) {
+		for _, authorization := range []string{"token ghp_revoked", "Bearer app.jwt.signature"} {
+			api := useFakeAPI(t, fakeResponse{Status: 401, Body: `{"message":"Bad credentials"}`})
+			req := newRequest(pdk.MethodGet, api.url+"/user")
+			req.SetHeader("Authorization", authorization)
+			resp := send(req)
+
+			requests := api.requests()
+			if resp.Status() != 401 || len(requests) != 1 {
+				t.Errorf("send() with %s = %d after %d requests, want 401 after 1", authorization, resp.Status(), len(requests))
+				continue
+			}
+			if got := requests[0].Headers["Authorization"]; got != authorization {
+				t.Errorf("Authorization = %q, want %q", got, authorization)
+			}
+		}
+	})
+
+	t.Run("reports an exhausted primary rate limit", func(t *testing.T) {
+		api := useFakeAPI(t, fakeResponse{
+			Status:  403,
+			Headers: map[string]string{"X-RateLimit-Limit": "60", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1700000000"},
+			Body:    `{"message":"API rate limit exceeded"}`,
+		})
+		resp := send(newRequest(pdk.MethodGet, api.url+"/users/octocat"))
+		if n := len(api.requests()); resp.Status() != 403 || n != 1 {
+			t.Errorf("send() = %d after %d requests, want 403 after 1", resp.Status(), n)
+		}
+
+		result := withRateLimit(CallToolResult{IsError: some(true)})
+		var block struct {
+			RateLimit RateLimit `json:"rate_limit"`
+		}
+		if len(result.Content) != 1 || json.Unmarshal([]byte(*result.Content[0].Text), &block) != nil {
+			t.Fatalf("withRateLimit() = %+v, want a rate_limit block", result.Content)
+		}
+		if block.RateLimit.Remaining != 0 || block.RateLimit.Reset != "2023-11-14T22:13:20Z" {
+			t.Errorf("rate_limit = %+v, want remaining 0 reset 2023-11-14T22:13:20Z", block.RateLimit)
+		}
+	})
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/extism/go-pdk"
+)
+
+func searchSchema(query, sort string) schema {
+	return schema{
+		"type": "object",
+		"properties": props{
+			"q":          prop("string", query),
+			"sort":       prop("string", sort),
+			"order":      prop("string", "Sort order when sort is given (asc or desc, default desc)"),
+			"text_match": prop("boolean", "Include highlighted snippets of the matching text (default true)"),
+			"per_page":   prop("integer", "Number of results per page (max 100)"),
+			"page":       prop("integer", "Page number for pagination"),
+		},
+		"required": []string{"q"},
+	}
+}
+
+var (
+	SearchCodeTool = ToolDescription{
+		Name:        "gh-search-code",
+		Description: "Search for code across GitHub repositories. Returns the path, repository, URL and matching snippets of each file, which can then be read with gh-get-file-contents",
+		InputSchema: searchSchema(
+			"The search keywords and qualifiers, e.g. `addClass in:file language:js repo:jquery/jquery`. Qualifiers include repo:, org:, user:, path:, filename:, extension:, language: and in:file/in:path",
+			"Sort field (indexed); defaults to best match",
+		),
+	}
+	SearchIssuesTool = ToolDescription{
+		Name:        "gh-search-issues",
+		Description: "Search for issues and pull requests across GitHub repositories",
+		InputSchema: searchSchema(
+			"The search keywords and qualifiers, e.g. `memory leak repo:owner/name is:issue is:open label:bug`. Use is:pr or type:pr for pull requests. Qualifiers include repo:, org:, author:, assignee:, mentions:, label:, state:, is:, in:title/in:body/in:comments, created: and updated:",
+			"Sort field (comments, reactions, interactions, created, updated); defaults to best match",
+		),
+	}
+	SearchCommitsTool = ToolDescription{
+		Name:        "gh-search-commits",
+		Description: "Search for commits by message across the default branches of GitHub repositories",
+		InputSchema: searchSchema(
+			"The search keywords and qualifiers, e.g. `fix race repo:owner/name author:octocat`. Qualifiers include repo:, org:, user:, author:, committer:, author-date:, committer-date:, merge:, hash: and parent:",
+			"Sort field (author-date, committer-date); defaults to best match",
+		),
+	}
+	SearchRepositoriesTool = ToolDescription{
+		Name:        "gh-search-repositories",
+		Description: "Search for GitHub repositories",
+		InputSchema: searchSchema(
+			"The search keywords and qualifiers, e.g. `mcp server language:go stars:>100`. Qualifiers include user:, org:, language:, topic:, stars:, forks:, size:, pushed:, created:, archived:, is:public/is:private and in:name/in:description/in:readme",
+			"Sort field (stars, forks, help-wanted-issues, updated); defaults to best match",
+		),
+	}
+	SearchTools = []ToolDescription{
+		SearchCodeTool,
+		SearchIssuesTool,
+		SearchCommitsTool,
+		SearchRepositoriesTool,
+	}
+)
+
+type TextMatch struct {
+	Fragment string `json:"fragment"`
+	Property string `json:"property"`
+}
+
+type SearchResult[T any] struct {
+	TotalCount        int  `json:"total_count"`
+	IncompleteResults bool `json:"incomplete_results"`
+	Items             []T  `json:"items"`
+}
+
+type CodeSearchItem struct {
+	Path       string `json:"path"`
+	Repository string `json:"repository"`
+	Sha        string `json:"sha"`
+	URL        string `json:"url"`
+	// Snippets are the text-match fragments around the search terms
+	Snippets []string `json:"snippets,omitempty"`
+}
+
+type IssueSearchItem struct {
+	Number      int      `json:"number"`
+	Title       string   `json:"title"`
+	State       string   `json:"state"`
+	PullRequest bool     `json:"pull_request,omitempty"`
+	Author      string   `json:"author"`
+	Repository  string   `json:"repository"`
+	URL         string   `json:"url"`
+	UpdatedAt   string   `json:"updated_at"`
+	Snippets    []string `json:"snippets,omitempty"`
+}
+
+type CommitSearchItem struct {
+	Sha        string   `json:"sha"`
+	Message    string   `json:"message"`
+	Author     string   `json:"author"`
+	Date       string   `json:"date"`
+	Repository string   `json:"repository"`
+	URL        string   `json:"url"`
+	Snippets   []string `json:"snippets,omitempty"`
+}
+
+type RepositorySearchItem struct {
+	Repository  string   `json:"repository"`
+	Description string   `json:"description,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Stars       int      `json:"stars"`
+	Forks       int      `json:"forks"`
+	Archived    bool     `json:"archived,omitempty"`
+	UpdatedAt   string   `json:"updated_at"`
+	URL         string   `json:"url"`
+	Snippets    []string `json:"snippets,omitempty"`
+}
+
+func snippets(matches []TextMatch) []string {
+	fragments := make([]string, 0, len(matches))
+	for _, m := range matches {
+		fragments = append(fragments, m.Fragment)
+	}
+	return fragments
+}
+
+// repositoryFromURL returns owner/name from an API repository URL such as
+// https://api.github.com/repos/owner/name.
+func repositoryFromURL(u string) string {
+	if i := strings.Index(u, "/repos/"); i >= 0 {
+		return u[i+len("/repos/"):]
+	}
+	return u
+}
+
+// searchGet runs a search against /search/{kind} and decodes the raw
+// response into result.
+func searchGet(apiKey, kind string, args map[string]interface{}, result any) error {
+	q, _ := args["q"].(string)
+	if q == "" {
+		return errors.New("q is required")
+	}
+
+	params := url.Values{}
+	params.Set("q", q)
+	if sort, ok := args["sort"].(string); ok && sort != "" {
+		params.Set("sort", sort)
+		if order, ok := args["order"].(string); ok && order != "" {
+			params.Set("order", order)
+		}
+	}
+
+	// Pagination parameters
+	perPage := 30 // Default value
+	if value, ok := args["per_page"].(float64); ok {
+		if value > 100 {
+			perPage = 100 // Max value
+		} else if value > 0 {
+			perPage = int(value)
+		}
+	}
+	params.Set("per_page", fmt.Sprint(perPage))
+
+	page := 1 // Default value
+	if value, ok := args["page"].(float64); ok && value > 0 {
+		page = int(value)
+	}
+	params.Set("page", fmt.Sprint(page))
+
+	u := fmt.Sprintf("%s/search/%s?%s", apiBase, kind, params.Encode())
+	pdk.Log(pdk.LogDebug, fmt.Sprint("Searching: ", u))
+
+	accept := "application/vnd.github.text-match+json"
+	if textMatch, ok := args["text_match"].(bool); ok && !textMatch {
+		accept = "application/vnd.github+json"
+	}
+
+	req := newRequest(pdk.MethodGet, u)
+	req.SetHeader("Authorization", fmt.Sprint("token ", apiKey))
+	req.SetHeader("Accept", accept)
+	req.SetHeader("User-Agent", "github-mcpx-servlet")
+
+	resp := send(req)
+	if resp.Status() != 200 {
+		return fmt.Errorf("%d %s", resp.Status(), string(resp.Body()))
+	}
+	if err := json.Unmarshal(resp.Body(), result); err != nil {
+		return fmt.Errorf("Failed to parse search results: %w", err)
+	}
+	return nil
+}
+
+func searchResult[T any](result SearchResult[T], err error, message string) CallToolResult {
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("%s: %s", message, err)),
+			}},
+		}
+	}
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+
+	res, err := json.Marshal(result)
+	if err != nil {
+		return CallToolResult{
+			IsError: some(true),
+			Content: []Content{{
+				Type: ContentTypeText,
+				Text: some(fmt.Sprintf("Failed to marshal response: %s", err)),
+			}},
+		}
+	}
+
+	return CallToolResult{
+		Content: []Content{{
+			Type: ContentTypeText,
+			Text: some(string(res)),
+		}},
+	}
+}
+
+func searchCode(apiKey string, args map[string]interface{}) CallToolResult {
+	var raw SearchResult[struct {
+		Path       string `json:"path"`
+		Sha        string `json:"sha"`
+		HTMLURL    string `json:"html_url"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		TextMatches []TextMatch `json:"text_matches"`
+	}]
+	err := searchGet(apiKey, "code", args, &raw)
+
+	result := SearchResult[CodeSearchItem]{TotalCount: raw.TotalCount, IncompleteResults: raw.IncompleteResults}
+	for _, item := range raw.Items {
+		result.Items = append(result.Items, CodeSearchItem{
+			Path:       item.Path,
+			Repository: item.Repository.FullName,
+			Sha:        item.Sha,
+			URL:        item.HTMLURL,
+			Snippets:   snippets(item.TextMatches),
+		})
+	}
+	return searchResult(result, err, "Failed to search code")
+}
+
+func searchIssues(apiKey string, args map[string]interface{}) CallToolResult {
+	var raw SearchResult[struct {
+		Number        int    `json:"number"`
+		Title         string `json:"title"`
+		State         string `json:"state"`
+		HTMLURL       string `json:"html_url"`
+		RepositoryURL string `json:"repository_url"`
+		UpdatedAt     string `json:"updated_at"`
+		User          struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		PullRequest *struct{}   `json:"pull_request"`
+		TextMatches []TextMatch `json:"text_matches"`
+	}]
+	err := searchGet(apiKey, "issues", args, &raw)
+
+	result := SearchResult[IssueSearchItem]{TotalCount: raw.TotalCount, IncompleteResults: raw.IncompleteResults}
+	for _, item := range raw.Items {
+		result.Items = append(result.Items, IssueSearchItem{
+			Number:      item.Number,
+			Title:       item.Title,
+			State:       item.State,
+			PullRequest: item.PullRequest != nil,
+			Author:      item.User.Login,
+			Repository:  repositoryFromURL(item.RepositoryURL),
+			URL:         item.HTMLURL,
+			UpdatedAt:   item.UpdatedAt,
+			Snippets:    snippets(item.TextMatches),
+		})
+	}
+	return searchResult(result, err, "Failed to search issues")
+}
+
+func searchCommits(apiKey string, args map[string]interface{}) CallToolResult {
+	var raw SearchResult[struct {
+		Sha     string `json:"sha"`
+		HTMLURL string `json:"html_url"`
+		Commit  struct {
+			Message string `json:"message"`
+			Author  Author `json:"author"`
+		} `json:"commit"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		TextMatches []TextMatch `json:"text_matches"`
+	}]
+	err := searchGet(apiKey, "commits", args, &raw)
+
+	result := SearchResult[CommitSearchItem]{TotalCount: raw.TotalCount, IncompleteResults: raw.IncompleteResults}
+	for _, item := range raw.Items {
+		// keep the subject line, the snippets show where the match is
+		message, _, _ := strings.Cut(item.Commit.Message, "\n")
+		result.Items = append(result.Items, CommitSearchItem{
+			Sha:        item.Sha,
+			Message:    message,
+			Author:     item.Commit.Author.Name,
+			Date:       item.Commit.Author.Date,
+			Repository: item.Repository.FullName,
+			URL:        item.HTMLURL,
+			Snippets:   snippets(item.TextMatches),
+		})
+	}
+	return searchResult(result, err, "Failed to search commits")
+}
+
+func searchRepositories(apiKey string, args map[string]interface{}) CallToolResult {
+	var raw SearchResult[struct {
+		FullName    string      `json:"full_name"`
+		Description string      `json:"description"`
+		Language    string      `json:"language"`
+		Stars       int         `json:"stargazers_count"`
+		Forks       int         `json:"forks_count"`
+		Archived    bool        `json:"archived"`
+		UpdatedAt   string      `json:"updated_at"`
+		HTMLURL     string      `json:"html_url"`
+		TextMatches []TextMatch `json:"text_matches"`
+	}]
+	err := searchGet(apiKey, "repositories", args, &raw)
+
+	result := SearchResult[RepositorySearchItem]{TotalCount: raw.TotalCount, IncompleteResults: raw.IncompleteResults}
+	for _, item := range raw.Items {
+		result.Items = append(result.Items, RepositorySearchItem{
+			Repository:  item.FullName,
+			Description: item.Description,
+			Language:    item.Language,
+			Stars:       item.Stars,
+			Forks:       item.Forks,
+			Archived:    item.Archived,
+			UpdatedAt:   item.UpdatedAt,
+			URL:         item.HTMLURL,
+			Snippets:    snippets(item.TextMatches),
+		})
+	}
+	return searchResult(result, err, "Failed to search repositories")
+}
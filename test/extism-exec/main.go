@@ -0,0 +1,147 @@
+// extism-exec runs a Go test binary built for wasip1 inside the Extism
+// runtime, so servlet unit tests can call the go-pdk host functions (config,
+// vars, logging and HTTP). go test picks it up from PATH under the name
+// go_wasip1_wasm_exec; `just unit-test` sets that up and runs the tests of
+// every servlet that has some. The host's temporary directory is mounted at
+// /tmp for tests that work with files.
+//
+// The tests can reach a scripted HTTP server on localhost, whose URL is in
+// the FAKE_API_URL environment variable. It stands in for the APIs servlets
+// call, so requests go through the real host functions:
+//
+//	POST /_fake/responses  queue responses, a JSON array of {status, headers, body}
+//	GET  /_fake/requests   the requests received since, as {method, path, headers, body}
+//
+// Any other request is recorded and answered with the next queued response.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+
+	extism "github.com/extism/go-sdk"
+	"github.com/tetratelabs/wazero"
+)
+
+type fakeResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+type fakeRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// fakeAPI answers requests with the queued responses and records them.
+type fakeAPI struct {
+	mu        sync.Mutex
+	responses []fakeResponse
+	requests  []fakeRequest
+}
+
+func (api *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/_fake/responses":
+		var responses []fakeResponse
+		if err := json.Unmarshal(body, &responses); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		api.responses, api.requests = responses, nil
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case "/_fake/requests":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(api.requests)
+		return
+	}
+
+	headers := map[string]string{}
+	for name := range r.Header {
+		headers[name] = r.Header.Get(name)
+	}
+	api.requests = append(api.requests, fakeRequest{
+		Method:  r.Method,
+		Path:    r.URL.RequestURI(),
+		Headers: headers,
+		Body:    string(body),
+	})
+
+	if len(api.responses) == 0 {
+		// 501 is never retried, so a missing response shows up as one
+		// unexpected request
+		http.Error(w, "no response queued", http.StatusNotImplemented)
+		return
+	}
+	resp := api.responses[0]
+	api.responses = api.responses[1:]
+	for name, value := range resp.Headers {
+		w.Header().Set(name, value)
+	}
+	w.WriteHeader(resp.Status)
+	io.WriteString(w, resp.Body)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: go_wasip1_wasm_exec <test.wasm> [args...]")
+		os.Exit(2)
+	}
+
+	server := httptest.NewServer(&fakeAPI{})
+	defer server.Close()
+
+	manifest := extism.Manifest{
+		Wasm:         []extism.Wasm{extism.WasmFile{Path: os.Args[1]}},
+		AllowedHosts: []string{"127.0.0.1"},
+		// t.TempDir() lives under /tmp, as do the files servlets may read
+		AllowedPaths: map[string]string{os.TempDir(): "/tmp"},
+	}
+	config := extism.PluginConfig{
+		EnableWasi:                true,
+		EnableHttpResponseHeaders: true,
+		ModuleConfig: wazero.NewModuleConfig().
+			WithArgs(os.Args[1:]...).
+			WithEnv("FAKE_API_URL", server.URL).
+			WithStdout(os.Stdout).
+			WithStderr(os.Stderr).
+			WithSysWalltime().
+			WithSysNanotime().
+			WithSysNanosleep(),
+	}
+
+	ctx := context.Background()
+	plugin, err := extism.NewPlugin(ctx, manifest, config, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load test binary:", err)
+		os.Exit(1)
+	}
+
+	// the test binary reports its result as the exit code of _start
+	exitCode, _, err := plugin.Call("_start", nil)
+	if err != nil && exitCode == 0 {
+		fmt.Fprintln(os.Stderr, err)
+		exitCode = 1
+	}
+	server.Close()
+	os.Exit(int(exitCode))
+}